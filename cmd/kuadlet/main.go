@@ -7,6 +7,7 @@ import (
 	"kuadlet/pkg/quadlet"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -17,6 +18,28 @@ import (
 var (
 	outputOneFile bool
 	splitOutput   bool
+	serviceType   string
+	nodePortRange string
+	exposure      string
+	ingressClass  string
+	gateway       string
+	netPolicies   bool
+	nativeProbes  bool
+	multus        bool
+	multusIPAM    string
+	passwdFile    string
+	groupFile     string
+	pss           string
+	harden        bool
+	requestRatio  float64
+	deviceMap     []string
+	kubeVersion   string
+	autoUpdate    []string
+	pullSecrets   bool
+	redact        bool
+	qualifyImages bool
+	registriesCfg []string
+	rewrites      []string
 )
 
 func main() {
@@ -34,6 +57,28 @@ func main() {
 
 	convertCmd.Flags().BoolVar(&outputOneFile, "one-file", true, "Output all manifests to stdout separated by '---' (default)")
 	convertCmd.Flags().BoolVar(&splitOutput, "split", false, "Write manifests to separate files in current directory (overrides --one-file)")
+	convertCmd.Flags().StringVar(&serviceType, "service-type", "ClusterIP", "Default Service type: ClusterIP, NodePort, LoadBalancer or Headless (overridable per unit with kuadlet.io/service-type)")
+	convertCmd.Flags().StringVar(&nodePortRange, "node-port-range", "30000-32767", "Node port range of the target cluster, used to map host ports to nodePort")
+	convertCmd.Flags().StringVar(&exposure, "exposure", "none", "Generate routing for HTTP services: none, ingress or httproute")
+	convertCmd.Flags().StringVar(&ingressClass, "ingress-class", "", "ingressClassName of generated Ingresses")
+	convertCmd.Flags().StringVar(&gateway, "gateway", "", "Parent Gateway of generated HTTPRoutes, as <name> or <namespace>/<name>")
+	convertCmd.Flags().BoolVar(&nativeProbes, "native-probes", false, "Translate health commands that curl, wget or nc localhost into httpGet and tcpSocket probes (apps must listen on the pod IP)")
+	convertCmd.Flags().BoolVar(&netPolicies, "network-policies", false, "Translate .network units into NetworkPolicies isolating their members")
+	convertCmd.Flags().BoolVar(&multus, "multus", false, "Translate .network units into Multus NetworkAttachmentDefinitions attached as secondary interfaces")
+	convertCmd.Flags().StringVar(&passwdFile, "passwd-file", "", "passwd file used to resolve user names in User= (e.g. the image's /etc/passwd)")
+	convertCmd.Flags().StringVar(&groupFile, "group-file", "", "group file used to resolve group names in User=, Group= and GroupAdd=")
+	convertCmd.Flags().Float64Var(&requestRatio, "request-ratio", 1, "Fraction of CPU and memory limits requested by units without a reservation (0 < ratio <= 1)")
+	convertCmd.Flags().StringArrayVar(&deviceMap, "device-resource", nil, "Map a host device path or directory to a device plugin resource, as <path>=<resource> (repeatable)")
+	convertCmd.Flags().StringVar(&kubeVersion, "kube-version", "", "Kubernetes version of the target cluster (e.g. 1.33), enabling newer fields such as lifecycle.stopSignal")
+	convertCmd.Flags().StringArrayVar(&autoUpdate, "auto-update-annotation", nil, "Annotation set on Deployments of units with AutoUpdate=registry, as <key>=<value> (repeatable, e.g. keel.sh/policy=force)")
+	convertCmd.Flags().BoolVar(&pullSecrets, "pull-secrets", false, "Generate imagePullSecrets from the AuthFile or Creds of .image units")
+	convertCmd.Flags().BoolVar(&redact, "redact", false, "Emit pull Secrets with placeholder credentials instead of real ones")
+	convertCmd.Flags().BoolVar(&qualifyImages, "qualify-images", false, "Fully qualify short image names, defaulting to docker.io/library")
+	convertCmd.Flags().StringArrayVar(&registriesCfg, "registries-conf", nil, "registries.conf or shortnames.conf whose short-name aliases and search registries qualify image names (repeatable, implies --qualify-images)")
+	convertCmd.Flags().StringArrayVar(&rewrites, "image-registry-rewrite", nil, "Rewrite a registry or repository prefix of image names, as <from>=<to> (repeatable, implies --qualify-images)")
+	convertCmd.Flags().StringVar(&pss, "pss", "none", "Check generated pod templates against a Pod Security Standard: none, baseline or restricted")
+	convertCmd.Flags().BoolVar(&harden, "harden", false, "Add secure defaults (drop ALL capabilities, RuntimeDefault seccomp, no privilege escalation) where units don't contradict them")
+	convertCmd.Flags().StringVar(&multusIPAM, "multus-ipam", "host-local", "IPAM plugin of Multus attachments for networks without IPAMDriver: host-local or whereabouts")
 
 	rootCmd.AddCommand(convertCmd)
 
@@ -69,6 +114,11 @@ func newRegistry() *Registry {
 }
 
 func runConvert(cmd *cobra.Command, args []string) error {
+	opts, err := buildOptions()
+	if err != nil {
+		return err
+	}
+
 	var inputFiles []string
 
 	// Recursive directory walk or just list files
@@ -167,6 +217,14 @@ func runConvert(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	opts.Networks = registry.Networks
+	opts.Containers = registry.Containers
+	opts.Images = registry.Images
+	opts.Builds = registry.Builds
+	if opts.PullSecrets {
+		opts.PullSecretsByRegistry = converter.BuildPullSecrets(opts)
+	}
+
 	// Pass 2: Convert
 	type result struct {
 		Name    string
@@ -193,9 +251,9 @@ func runConvert(cmd *cobra.Command, args []string) error {
 		switch ext {
 		case ".container":
 			if c, ok := registry.Containers[name]; ok {
-				if c.Container.Pod != "" {
+				if pod := converter.ContainerPod(c); pod != "" {
 					safeFilename := sanitize(filename)
-					safePod := sanitize(c.Container.Pod)
+					safePod := sanitize(pod)
 					// Check if the pod is also being processed?
 					// If the pod is in registry.Pods, we might not want to output this standalone.
 					// However, the report says: "It also generates a standalone duplicate Deployment for the container (with a warning)."
@@ -209,7 +267,7 @@ func runConvert(cmd *cobra.Command, args []string) error {
 					fmt.Fprintf(os.Stderr, "Warning: Container %s belongs to pod %s. Converting as standalone Deployment (pod wrapper logic not applied).\n", safeFilename, safePod) // #nosec G705
 				}
 				// We need to pass the registry for volume lookup
-				objects, convertErr = converter.ConvertContainer(c, name, registry.Volumes)
+				objects, convertErr = converter.ConvertContainer(c, name, registry.Volumes, opts)
 			}
 		case ".volume":
 			if v, ok := registry.Volumes[name]; ok {
@@ -224,13 +282,13 @@ func runConvert(cmd *cobra.Command, args []string) error {
 				// This replaces `findContainersForPod`
 				for cName, cUnit := range registry.Containers {
 					// Check if container belongs to this pod
-					// Pod reference can be "podname" or "podname.pod"
-					if cUnit.Container.Pod == name || cUnit.Container.Pod == name+".pod" {
+					// Pod reference can be "podname", "podname.pod" or Network=pod:podname
+					if converter.ContainerPod(cUnit) == name {
 						podContainers = append(podContainers, cUnit)
 						containerNames = append(containerNames, cName)
 					}
 				}
-				objects, convertErr = converter.ConvertPod(p, podContainers, containerNames, name, registry.Volumes)
+				objects, convertErr = converter.ConvertPod(p, podContainers, containerNames, name, registry.Volumes, opts)
 			}
 		case ".kube":
 			if k, ok := registry.Kubes[name]; ok {
@@ -238,11 +296,11 @@ func runConvert(cmd *cobra.Command, args []string) error {
 			}
 		case ".network":
 			if n, ok := registry.Networks[name]; ok {
-				objects, convertErr = converter.ConvertNetwork(n, name)
+				objects, convertErr = converter.ConvertNetwork(n, name, opts)
 			}
 		case ".image":
 			if i, ok := registry.Images[name]; ok {
-				objects, convertErr = converter.ConvertImage(i, name)
+				objects, convertErr = converter.ConvertImage(i, name, opts)
 			}
 		case ".build":
 			if b, ok := registry.Builds[name]; ok {
@@ -318,6 +376,130 @@ func runConvert(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// buildOptions translates the command line flags into converter options.
+func buildOptions() (*converter.Options, error) {
+	opts := &converter.Options{}
+
+	t, err := converter.ParseServiceType(serviceType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --service-type: %w", err)
+	}
+	opts.ServiceType = t
+
+	minStr, maxStr, ok := strings.Cut(nodePortRange, "-")
+	minPort, errMin := strconv.ParseInt(minStr, 10, 32)
+	maxPort, errMax := strconv.ParseInt(maxStr, 10, 32)
+	if !ok || errMin != nil || errMax != nil || minPort < 1 || maxPort > 65535 || minPort > maxPort {
+		return nil, fmt.Errorf("invalid --node-port-range %q (expected <min>-<max>)", nodePortRange)
+	}
+	opts.NodePortMin = int32(minPort)
+	opts.NodePortMax = int32(maxPort)
+
+	e, err := converter.ParseExposure(exposure)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --exposure: %w", err)
+	}
+	opts.Exposure = e
+	opts.IngressClassName = ingressClass
+	if ns, name, ok := strings.Cut(gateway, "/"); ok {
+		opts.GatewayNamespace = ns
+		opts.GatewayName = name
+	} else {
+		opts.GatewayName = gateway
+	}
+	if opts.Exposure == converter.ExposureHTTPRoute && opts.GatewayName == "" {
+		return nil, fmt.Errorf("--exposure httproute requires --gateway")
+	}
+	opts.NativeProbes = nativeProbes
+	opts.NetworkPolicies = netPolicies
+	opts.Multus = multus
+	if opts.MultusIPAM, err = converter.ParseIPAM(multusIPAM); err != nil {
+		return nil, fmt.Errorf("invalid --multus-ipam: %w", err)
+	}
+	if requestRatio <= 0 || requestRatio > 1 {
+		return nil, fmt.Errorf("invalid --request-ratio %g: must be greater than 0 and at most 1", requestRatio)
+	}
+	opts.RequestRatio = requestRatio
+	opts.PullSecrets = pullSecrets
+	opts.Redact = redact
+	if kubeVersion != "" {
+		if opts.KubeVersion, err = converter.ParseKubeVersion(kubeVersion); err != nil {
+			return nil, fmt.Errorf("invalid --kube-version: %w", err)
+		}
+	}
+	for _, a := range autoUpdate {
+		key, value, err := converter.ParseAutoUpdateAnnotation(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --auto-update-annotation: %w", err)
+		}
+		if opts.AutoUpdateAnnotations == nil {
+			opts.AutoUpdateAnnotations = make(map[string]string)
+		}
+		opts.AutoUpdateAnnotations[key] = value
+	}
+	for _, d := range deviceMap {
+		path, resourceName, err := converter.ParseDeviceResource(d)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --device-resource: %w", err)
+		}
+		if opts.DeviceResources == nil {
+			opts.DeviceResources = make(map[string]string)
+		}
+		opts.DeviceResources[path] = resourceName
+	}
+	if opts.PodSecurity, err = converter.ParsePodSecurityLevel(pss); err != nil {
+		return nil, fmt.Errorf("invalid --pss: %w", err)
+	}
+	opts.Harden = harden
+	opts.QualifyImages = qualifyImages || len(registriesCfg) > 0
+	for _, r := range rewrites {
+		rewrite, err := converter.ParseRegistryRewrite(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --image-registry-rewrite: %w", err)
+		}
+		opts.RegistryRewrites = append(opts.RegistryRewrites, rewrite)
+	}
+
+	if passwdFile != "" {
+		// #nosec G304
+		f, err := os.Open(passwdFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open --passwd-file: %w", err)
+		}
+		opts.Passwd, err = converter.ParsePasswd(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", passwdFile, err)
+		}
+	}
+	if groupFile != "" {
+		// #nosec G304
+		f, err := os.Open(groupFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open --group-file: %w", err)
+		}
+		opts.Groups, err = converter.ParseGroup(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", groupFile, err)
+		}
+	}
+	for _, path := range registriesCfg {
+		// #nosec G304
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open --registries-conf: %w", err)
+		}
+		err = converter.ParseRegistriesConf(f, &opts.Registries)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	return opts, nil
+}
+
 func isSupportedExtension(ext string) bool {
 	switch ext {
 	case ".container", ".volume", ".pod", ".kube", ".network", ".image", ".build", ".artifact":
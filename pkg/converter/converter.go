@@ -2,19 +2,16 @@ package converter
 
 import (
 	"fmt"
-	"math"
 	"kuadlet/pkg/quadlet"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
-	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func ConvertVolume(v *quadlet.VolumeUnit, name string) ([]runtime.Object, error) {
@@ -45,15 +42,90 @@ func ConvertVolume(v *quadlet.VolumeUnit, name string) ([]runtime.Object, error)
 }
 
 // ConvertContainer now accepts a volume registry to lookup actual VolumeName
-func ConvertContainer(c *quadlet.ContainerUnit, name string, volumeRegistry map[string]*quadlet.VolumeUnit) ([]runtime.Object, error) {
-	container, volumes, servicePorts, err := createContainerSpec(c, name, volumeRegistry)
+func ConvertContainer(c *quadlet.ContainerUnit, name string, volumeRegistry map[string]*quadlet.VolumeUnit, opts *Options) ([]runtime.Object, error) {
+	o := opts.withDefaults()
+	serviceType, err := resolveServiceType(containerSetting(c, SettingServiceType), o)
+	if err != nil {
+		return nil, fmt.Errorf("container %s: %w", name, err)
+	}
+
+	// Containers sharing a network namespace must run in the same pod: the
+	// namespace owner's Deployment carries all of them.
+	var members []string
+	if ContainerPod(c) == "" {
+		if _, unresolved := sharedNamespaceTarget(name, c, o.Containers); unresolved != "" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Network=%s does not match any converted container; ignoring.\n", sanitize(name), sanitize(unresolved))
+		}
+		owner, err := sharedNamespaceOwner(name, c, o.Containers)
+		if err != nil {
+			return nil, err
+		}
+		if owner != name {
+			into := "the " + owner + " Deployment"
+			if pod := ContainerPod(o.Containers[owner]); pod != "" {
+				into = "the Deployment of pod " + pod
+			}
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s shares the network namespace of %s; it is co-located into %s.\n", sanitize(name), sanitize(owner), sanitize(into))
+			return nil, nil
+		}
+		if members, err = sharedNamespaceMembers(name, o.Containers); err != nil {
+			return nil, err
+		}
+	}
+
+	container, volumes, published, err := createContainerSpec(c, name, volumeRegistry, o)
 	if err != nil {
 		return nil, err
 	}
+	containers := []corev1.Container{*container}
+	routeSources := []*quadlet.ContainerUnit{c}
+	routeNames := []string{name}
+	for _, m := range members {
+		mc := o.Containers[m]
+		mContainer, mVolumes, mPublished, err := createContainerSpec(mc, m, volumeRegistry, o)
+		if err != nil {
+			return nil, err
+		}
+		if len(mPublished.Container) > 0 {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: PublishPort is ignored; ports of a shared network namespace are published by its owner %s.\n", sanitize(m), sanitize(name))
+		}
+		prefixVolumes(m, mContainer, mVolumes)
+		containers = append(containers, *mContainer)
+		volumes = append(volumes, mVolumes...)
+		routeSources = append(routeSources, mc)
+		routeNames = append(routeNames, m)
+	}
 
 	labels := map[string]string{
 		"app.kubernetes.io/name": name,
 	}
+
+	var routes []httpRoute
+	for i, rc := range routeSources {
+		cRoutes, err := collectRoutes(routeNames[i], rc.Container.Label, rc.Container.Annotation)
+		if err != nil {
+			return nil, fmt.Errorf("container %s: %w", routeNames[i], err)
+		}
+		routes = append(routes, cRoutes...)
+	}
+	routeObjects, err := exposeRoutes(name, labels, routes, published, o)
+	if err != nil {
+		return nil, err
+	}
+	// Routed ports that were not published still need to be declared.
+	for _, sp := range published.Service {
+		if !hasContainerPort(&containers[0], sp.TargetPort.IntVal, sp.Protocol) {
+			containers[0].Ports = append(containers[0].Ports, corev1.ContainerPort{
+				Name:          sp.Name,
+				ContainerPort: sp.TargetPort.IntVal,
+				Protocol:      sp.Protocol,
+			})
+		}
+	}
+
 	replicas := int32(1)
 
 	deployment := &appsv1.Deployment{
@@ -75,44 +147,122 @@ func ConvertContainer(c *quadlet.ContainerUnit, name string, volumeRegistry map[
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{*container},
+					Containers: containers,
 					Volumes:    volumes,
 				},
 			},
 		},
 	}
 
+	applyNetworkMode(name, &deployment.Spec.Template.Spec, c.Container.Network)
+	joined := unitNetworks(name, c.Container.Network, o.Networks)
+	policyObjects := applyNetworkMembership(name, &deployment.Spec.Template, joined, published, o)
+	if err := applyMultusNetworks("container "+name, &deployment.Spec.Template, joined, c.Container.IP, c.Container.IP6, o); err != nil {
+		return nil, err
+	}
+	var dns dnsSettings
+	dns.addContainer(c)
+	podUnits := []*quadlet.ContainerUnit{c}
+	for _, m := range members {
+		dns.addContainer(o.Containers[m])
+		podUnits = append(podUnits, o.Containers[m])
+	}
+	applyPodSecurity("container "+name, &deployment.Spec.Template.Spec, podUnits, o)
+	applyRunInit("container "+name, &deployment.Spec.Template.Spec, podUnits)
+	if err := applySysctls("container "+name, &deployment.Spec.Template.Spec, podUnits); err != nil {
+		return nil, err
+	}
+	if err := applyStopSettings(&deployment.Spec.Template.Spec, podUnits, o); err != nil {
+		return nil, err
+	}
+	if err := applyImagePlatform("container "+name, &deployment.Spec.Template.Spec, podUnits, o); err != nil {
+		return nil, err
+	}
+	deployment.Annotations = mergeMaps(deployment.Annotations, autoUpdateAnnotations(podUnits, o))
+	applyPullSecrets(&deployment.Spec.Template.Spec, o)
+	if err := applyDNS("container "+name, &deployment.Spec.Template.Spec, dns, joined, o); err != nil {
+		return nil, err
+	}
+	applyPodSecurityStandard("container "+name, &deployment.Spec.Template.Spec, podUnits, o)
+
 	var objects []runtime.Object
 	objects = append(objects, deployment)
 
-	if len(servicePorts) > 0 {
-		service := &corev1.Service{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "v1",
-				Kind:       "Service",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:   name,
-				Labels: labels,
-			},
-			Spec: corev1.ServiceSpec{
-				Selector: labels,
-				Ports:    servicePorts,
-				Type:     corev1.ServiceTypeClusterIP,
-			},
-		}
+	taken := make(map[string]bool)
+	service := newService(name, labels, published, serviceType, o)
+	if service != nil {
 		objects = append(objects, service)
+		taken[name] = true
+	}
+	governing, err := applyHostName(name, c.Container.HostName, &deployment.Spec.Template.Spec, labels, service)
+	if err != nil {
+		return nil, fmt.Errorf("container %s: %w", name, err)
+	}
+	if governing != nil {
+		objects = append(objects, governing)
+		taken[governing.Name] = true
+	}
+	if onUserNetwork(c.Container.Network) {
+		dnsNames := []string{containerDNSName(c, name)}
+		dnsNames = append(dnsNames, c.Container.NetworkAlias...)
+		dnsNames = append(dnsNames, networkAliases(c.Container.Network)...)
+		for _, m := range members {
+			dnsNames = append(dnsNames, containerDNSName(o.Containers[m], m))
+		}
+		objects = append(objects, peerServices(name, dnsNames, labels, &deployment.Spec.Template.Spec, serviceType, service, taken)...)
 	}
 
+	meta := newUnitMetadata("container "+name, labels)
+	meta.addLabels(name, c.Container.Label)
+	meta.addAnnotations(name, c.Container.Annotation)
+	for _, m := range members {
+		meta.addLabels(m, o.Containers[m].Container.Label)
+		meta.addAnnotations(m, o.Containers[m].Container.Annotation)
+	}
+	meta.apply(objects)
+
+	objects = append(objects, routeObjects...)
+	objects = append(objects, policyObjects...)
+
 	return objects, nil
 }
 
-func ConvertPod(p *quadlet.PodUnit, containers []*quadlet.ContainerUnit, containerNames []string, name string, volumeRegistry map[string]*quadlet.VolumeUnit) ([]runtime.Object, error) {
+func ConvertPod(p *quadlet.PodUnit, containers []*quadlet.ContainerUnit, containerNames []string, name string, volumeRegistry map[string]*quadlet.VolumeUnit, opts *Options) ([]runtime.Object, error) {
+	o := opts.withDefaults()
+	serviceType, err := resolveServiceType(podSetting(p, SettingServiceType), o)
+	if err != nil {
+		return nil, fmt.Errorf("pod %s: %w", name, err)
+	}
+	// PodName names the pod in Podman; use it for the Deployment and Service.
+	if p.Pod.PodName != "" {
+		if err := validateDNSLabel("PodName", p.Pod.PodName); err != nil {
+			return nil, fmt.Errorf("pod %s: %w", name, err)
+		}
+		name = p.Pod.PodName
+	}
+
 	var objects []runtime.Object
 	labels := map[string]string{
 		"app.kubernetes.io/name": name,
 	}
 
+	// Containers joining the network namespace of a pod container run in
+	// the pod as well. They follow the pod's containers and don't mount the
+	// pod's volumes.
+	unitCount := len(containers)
+	containers = slices.Clone(containers)
+	containerNames = slices.Clone(containerNames)
+	for _, cName := range containerNames[:unitCount] {
+		members, err := sharedNamespaceMembers(cName, o.Containers)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			containers = append(containers, o.Containers[m])
+			containerNames = append(containerNames, m)
+		}
+	}
+
 	var podContainers []corev1.Container
 	var podVolumes []corev1.Volume
 	var podVolumeMounts []corev1.VolumeMount
@@ -128,26 +278,21 @@ func ConvertPod(p *quadlet.PodUnit, containers []*quadlet.ContainerUnit, contain
 
 	for i, c := range containers {
 		cName := containerNames[i]
-		container, cVolumes, _, err := createContainerSpec(c, cName, volumeRegistry)
+		container, cVolumes, cPublished, err := createContainerSpec(c, cName, volumeRegistry, o)
 		if err != nil {
 			return nil, err
 		}
 
-		for j := range cVolumes {
-			oldName := cVolumes[j].Name
-			newName := fmt.Sprintf("%s-%s", cName, oldName)
-			cVolumes[j].Name = newName
+		prefixVolumes(cName, container, cVolumes)
 
-			for k := range container.VolumeMounts {
-				if container.VolumeMounts[k].Name == oldName {
-					container.VolumeMounts[k].Name = newName
-				}
-			}
+		if i < unitCount {
+			// Mount pod-level volumes into the container
+			container.VolumeMounts = append(container.VolumeMounts, podVolumeMounts...)
+		} else if len(cPublished.Container) > 0 {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: PublishPort is ignored; ports of a shared network namespace are published by its owner pod %s.\n", sanitize(cName), sanitize(name))
 		}
 
-		// Mount pod-level volumes into the container
-		container.VolumeMounts = append(container.VolumeMounts, podVolumeMounts...)
-
 		podContainers = append(podContainers, *container)
 		podVolumes = append(podVolumes, cVolumes...)
 	}
@@ -180,43 +325,135 @@ func ConvertPod(p *quadlet.PodUnit, containers []*quadlet.ContainerUnit, contain
 	}
 	objects = append(objects, deployment)
 
-	var servicePorts []corev1.ServicePort
-	seenServicePorts := make(map[int32]string)
+	published, err := expandPublishPorts(p.Pod.PublishPort, "pod-port", " in Pod", "pod "+name)
+	if err != nil {
+		return nil, err
+	}
 
-	for i, portSpec := range p.Pod.PublishPort {
-		_, _, sPort, err := parsePortSpec(portSpec, fmt.Sprintf("pod-port-%d", i))
+	// Routes may be declared on the pod or on any of its containers;
+	// routeOwners[i] is the container index of routes[i], -1 for the pod.
+	routes, err := collectRoutes(name, p.Pod.Label, nil)
+	if err != nil {
+		return nil, fmt.Errorf("pod %s: %w", name, err)
+	}
+	routeOwners := make([]int, len(routes))
+	for i := range routeOwners {
+		routeOwners[i] = -1
+	}
+	for i, c := range containers {
+		cRoutes, err := collectRoutes(containerNames[i], c.Container.Label, c.Container.Annotation)
 		if err != nil {
+			return nil, fmt.Errorf("container %s: %w", containerNames[i], err)
+		}
+		routes = append(routes, cRoutes...)
+		for range cRoutes {
+			routeOwners = append(routeOwners, i)
+		}
+	}
+	publishedCount := len(published.Service)
+	routeObjects, err := exposeRoutes(name, labels, routes, published, o)
+	if err != nil {
+		return nil, err
+	}
+	// Routed ports that were not published still need to be declared, on
+	// the container the route came from, or the first for pod routes.
+	podSpec := &deployment.Spec.Template.Spec
+	for _, sp := range published.Service[publishedCount:] {
+		if len(podSpec.Containers) == 0 {
+			break
+		}
+		declared := slices.ContainsFunc(podSpec.Containers, func(c corev1.Container) bool {
+			return hasContainerPort(&c, sp.TargetPort.IntVal, sp.Protocol)
+		})
+		if declared {
 			continue
 		}
-
-		// Check for duplicate host port
-		if definedIn, ok := seenServicePorts[sPort.Port]; ok {
-			return nil, fmt.Errorf("duplicate port definition detected in Pod: port %d is already defined in %s", sPort.Port, definedIn)
+		owner := 0
+		for i, r := range routes {
+			if r.Port == sp.TargetPort.IntVal && routeOwners[i] >= 0 {
+				owner = routeOwners[i]
+				break
+			}
 		}
-		seenServicePorts[sPort.Port] = fmt.Sprintf("PublishPort index %d", i)
+		podSpec.Containers[owner].Ports = append(podSpec.Containers[owner].Ports, corev1.ContainerPort{
+			Name:          sp.Name,
+			ContainerPort: sp.TargetPort.IntVal,
+			Protocol:      sp.Protocol,
+		})
+	}
 
-		servicePorts = append(servicePorts, *sPort)
+	// Containers in a pod share the pod's network namespace.
+	applyNetworkMode("pod "+name, &deployment.Spec.Template.Spec, p.Pod.Network)
+	joined := unitNetworks(name, p.Pod.Network, o.Networks)
+	policyObjects := applyNetworkMembership(name, &deployment.Spec.Template, joined, published, o)
+	if err := applyMultusNetworks("pod "+name, &deployment.Spec.Template, joined, p.Pod.IP, p.Pod.IP6, o); err != nil {
+		return nil, err
+	}
+	var dns dnsSettings
+	dns.addPod(p)
+	for _, c := range containers {
+		dns.addContainer(c)
+	}
+	applyPodSecurity("pod "+name, &deployment.Spec.Template.Spec, containers, o)
+	applyRunInit("pod "+name, &deployment.Spec.Template.Spec, containers)
+	if err := applySysctls("pod "+name, &deployment.Spec.Template.Spec, containers); err != nil {
+		return nil, err
+	}
+	if err := applyStopSettings(&deployment.Spec.Template.Spec, containers, o); err != nil {
+		return nil, err
+	}
+	if err := applyImagePlatform("pod "+name, &deployment.Spec.Template.Spec, containers, o); err != nil {
+		return nil, err
+	}
+	deployment.Annotations = mergeMaps(deployment.Annotations, autoUpdateAnnotations(containers, o))
+	applyPullSecrets(&deployment.Spec.Template.Spec, o)
+	if err := applyDNS("pod "+name, &deployment.Spec.Template.Spec, dns, joined, o); err != nil {
+		return nil, err
 	}
+	applyPodSecurityStandard("pod "+name, &deployment.Spec.Template.Spec, containers, o)
 
-	if len(servicePorts) > 0 {
-		service := &corev1.Service{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "v1",
-				Kind:       "Service",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:   name,
-				Labels: labels,
-			},
-			Spec: corev1.ServiceSpec{
-				Selector: labels,
-				Ports:    servicePorts,
-				Type:     corev1.ServiceTypeClusterIP,
-			},
-		}
+	taken := make(map[string]bool)
+	service := newService(name, labels, published, serviceType, o)
+	if service != nil {
 		objects = append(objects, service)
+		taken[name] = true
+	}
+	// Containers share the pod's UTS namespace, so only the pod's HostName applies.
+	for i, c := range containers {
+		if c.Container.HostName != "" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: HostName is ignored in pod %s; set HostName on the pod instead.\n", sanitize(containerNames[i]), sanitize(name))
+		}
+	}
+	governing, err := applyHostName(name, p.Pod.HostName, &deployment.Spec.Template.Spec, labels, service)
+	if err != nil {
+		return nil, fmt.Errorf("pod %s: %w", name, err)
+	}
+	if governing != nil {
+		objects = append(objects, governing)
+		taken[governing.Name] = true
+	}
+	if onUserNetwork(p.Pod.Network) {
+		dnsNames := []string{podDNSName(p, name)}
+		dnsNames = append(dnsNames, p.Pod.NetworkAlias...)
+		dnsNames = append(dnsNames, networkAliases(p.Pod.Network)...)
+		for i, c := range containers {
+			dnsNames = append(dnsNames, containerDNSName(c, containerNames[i]))
+		}
+		objects = append(objects, peerServices(name, dnsNames, labels, &deployment.Spec.Template.Spec, serviceType, service, taken)...)
 	}
 
+	meta := newUnitMetadata("pod "+name, labels)
+	meta.addLabels("pod "+name, p.Pod.Label)
+	for i, c := range containers {
+		meta.addLabels(containerNames[i], c.Container.Label)
+		meta.addAnnotations(containerNames[i], c.Container.Annotation)
+	}
+	meta.apply(objects)
+
+	objects = append(objects, routeObjects...)
+	objects = append(objects, policyObjects...)
+
 	return objects, nil
 }
 
@@ -227,14 +464,50 @@ func ConvertKube(k *quadlet.KubeUnit, name string) ([]runtime.Object, error) {
 	return nil, nil
 }
 
-func ConvertNetwork(n *quadlet.NetworkUnit, name string) ([]runtime.Object, error) {
+func ConvertNetwork(n *quadlet.NetworkUnit, name string, opts *Options) ([]runtime.Object, error) {
+	o := opts.withDefaults()
+	if o.Multus || o.NetworkPolicies {
+		var objects []runtime.Object
+		if o.Multus {
+			nad, err := networkAttachment(n, name, o)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, nad)
+		}
+		if o.NetworkPolicies {
+			objects = append(objects, networkPolicies(n, name, o)...)
+		} else {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: .network unit %s: network isolation is not mapped; members can reach any pod. Use --network-policies to isolate them.\n", sanitize(name))
+		}
+		return objects, nil
+	}
+
 	safeName := sanitize(name)
 	// #nosec G705
 	fmt.Fprintf(os.Stderr, "Warning: .network unit %s detected. Kubernetes handles networking differently (CNI). Podman network configurations do not directly map to Kubernetes resources.\n", safeName)
 	return nil, nil
 }
 
-func ConvertImage(i *quadlet.ImageUnit, name string) ([]runtime.Object, error) {
+func ConvertImage(i *quadlet.ImageUnit, name string, opts *Options) ([]runtime.Object, error) {
+	o := opts.withDefaults()
+	if o.PullSecrets && hasPullCredentials(i) {
+		// Emit the Secret pods were given; build it here only for a unit
+		// outside the prepared set.
+		secrets := o.PullSecretsByRegistry
+		if secrets == nil || o.Images[name] != i {
+			secrets = buildPullSecrets(map[string]*quadlet.ImageUnit{name: i}, o)
+		}
+		for _, secret := range secrets[imageRegistry(unitImage(i, o))] {
+			if secret.Name == pullSecretName(name) {
+				return []runtime.Object{secret}, nil
+			}
+		}
+		// The failure was reported when building the Secrets.
+		return nil, nil
+	}
+
 	safeName := sanitize(name)
 	// #nosec G705
 	fmt.Fprintf(os.Stderr, "Warning: .image unit %s detected. Kubernetes pulls images automatically on Pod scheduling. Explicit image pull units are not typically needed or supported as standalone resources.\n", safeName)
@@ -255,7 +528,23 @@ func ConvertArtifact(a *quadlet.ArtifactUnit, name string) ([]runtime.Object, er
 	return nil, nil
 }
 
-func createContainerSpec(c *quadlet.ContainerUnit, name string, volumeRegistry map[string]*quadlet.VolumeUnit) (*corev1.Container, []corev1.Volume, []corev1.ServicePort, error) {
+// prefixVolumes renames a container's volumes after the container so they
+// don't collide with those of other containers in the same pod.
+func prefixVolumes(prefix string, container *corev1.Container, volumes []corev1.Volume) {
+	for j := range volumes {
+		oldName := volumes[j].Name
+		newName := fmt.Sprintf("%s-%s", prefix, oldName)
+		volumes[j].Name = newName
+
+		for k := range container.VolumeMounts {
+			if container.VolumeMounts[k].Name == oldName {
+				container.VolumeMounts[k].Name = newName
+			}
+		}
+	}
+}
+
+func createContainerSpec(c *quadlet.ContainerUnit, name string, volumeRegistry map[string]*quadlet.VolumeUnit, opts Options) (*corev1.Container, []corev1.Volume, *publishedPorts, error) {
 	var env []corev1.EnvVar
 	for k, v := range c.Container.Environment {
 		env = append(env, corev1.EnvVar{
@@ -278,27 +567,13 @@ func createContainerSpec(c *quadlet.ContainerUnit, name string, volumeRegistry m
 		command = []string{c.Container.Entrypoint}
 	}
 
-	var containerPorts []corev1.ContainerPort
-	var servicePorts []corev1.ServicePort
-
-	// Deduplication check for Service Ports
-	// Key: port (host port)
-	seenServicePorts := make(map[int32]string)
-
-	for i, portSpec := range c.Container.PublishPort {
-		cPort, _, sPort, err := parsePortSpec(portSpec, fmt.Sprintf("port-%d", i))
-		if err != nil {
-			continue
-		}
-
-		// Check for duplicate host port
-		if definedIn, ok := seenServicePorts[sPort.Port]; ok {
-			return nil, nil, nil, fmt.Errorf("duplicate port definition detected: port %d is already defined in %s", sPort.Port, definedIn)
-		}
-		seenServicePorts[sPort.Port] = fmt.Sprintf("PublishPort index %d", i)
-
-		containerPorts = append(containerPorts, *cPort)
-		servicePorts = append(servicePorts, *sPort)
+	published, err := expandPublishPorts(c.Container.PublishPort, "port", "", name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ports, err := exposeHostPorts(c.Container.ExposeHostPort, published.Container)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("container %s: %w", name, err)
 	}
 
 	var volumeMounts []corev1.VolumeMount
@@ -314,95 +589,34 @@ func createContainerSpec(c *quadlet.ContainerUnit, name string, volumeRegistry m
 	}
 
 	// Probes
-	var livenessProbe *corev1.Probe
-	if c.Container.HealthCmd != "" {
-		// "none" disables it
-		if c.Container.HealthCmd != "none" {
-			// If it looks like a JSON array or explicitly starts with sh -c, maybe we could trust it,
-			// but to be safe and consistent with typical shell usage in HealthCmd, we wrap it.
-			// However, if it IS an array (starts with [), we should probably parse it as such?
-			// Quadlet docs say HealthCmd is "command to run". Podman treats it as CMD-SHELL if string.
-			// So wrapping in sh -c is the correct default for a string.
-			// Exception: if it starts with `["`, it might be an exec array?
-			// But Quadlet parser reads it as a raw string.
-			// Let's assume it's a shell command string.
-
-			// Optimization: if it already starts with "sh -c" or "/bin/sh -c", we might split it naively?
-			// No, safer to just wrap it unless we want to parse it.
-			// But wait, if the user wrote `HealthCmd=curl ...`, we want `sh -c "curl ..."`.
-			// If we use SplitArgs, we get `["curl", "..."]`. This FAILS for `curl ... || exit 1`.
-			// So we MUST use `sh -c`.
-			probeCmd := []string{"sh", "-c", c.Container.HealthCmd}
-
-			livenessProbe = &corev1.Probe{
-				ProbeHandler: corev1.ProbeHandler{
-					Exec: &corev1.ExecAction{
-						Command: probeCmd,
-					},
-				},
-			}
+	probes, err := createProbes(c, name, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-				if c.Container.HealthInterval != "" {
-					if d, err := time.ParseDuration(c.Container.HealthInterval); err == nil {
-						if d.Seconds() > math.MaxInt32 {
-							livenessProbe.PeriodSeconds = math.MaxInt32
-						} else {
-							livenessProbe.PeriodSeconds = int32(d.Seconds())
-						}
-					}
-				}
-				if c.Container.HealthTimeout != "" {
-					if d, err := time.ParseDuration(c.Container.HealthTimeout); err == nil {
-						if d.Seconds() > math.MaxInt32 {
-							livenessProbe.TimeoutSeconds = math.MaxInt32
-						} else {
-							livenessProbe.TimeoutSeconds = int32(d.Seconds())
-						}
-					}
-				}
-				if c.Container.HealthStartPeriod != "" {
-					if d, err := time.ParseDuration(c.Container.HealthStartPeriod); err == nil {
-						if d.Seconds() > math.MaxInt32 {
-							livenessProbe.InitialDelaySeconds = math.MaxInt32
-						} else {
-							livenessProbe.InitialDelaySeconds = int32(d.Seconds())
-						}
-					}
-				}
-				if c.Container.HealthRetries > 0 {
-					if c.Container.HealthRetries > math.MaxInt32 {
-						livenessProbe.FailureThreshold = math.MaxInt32
-					} else {
-						livenessProbe.FailureThreshold = int32(c.Container.HealthRetries)
-					}
-				}
-		}
+	image, err := resolveImage(c.Container.Image, name, opts)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("container %s: %w", name, err)
 	}
+	image = normalizeImage(image, name, opts)
 
-	// Resources
-	resources := corev1.ResourceRequirements{}
-	if c.Container.Memory != "" {
-		if q, err := resource.ParseQuantity(c.Container.Memory); err == nil {
-			resources.Limits = corev1.ResourceList{corev1.ResourceMemory: q}
-			resources.Requests = corev1.ResourceList{corev1.ResourceMemory: q}
-		}
+	resources, err := containerResources(c, name, opts)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("container %s: %w", name, err)
 	}
 
 	// SecurityContext
 	securityContext := &corev1.SecurityContext{}
 	hasSecurityContext := false
 
-	if c.Container.User != "" {
-		if uid, err := strconv.ParseInt(c.Container.User, 10, 64); err == nil {
-			securityContext.RunAsUser = &uid
-			hasSecurityContext = true
-		}
-	}
-	if c.Container.Group != "" {
-		if gid, err := strconv.ParseInt(c.Container.Group, 10, 64); err == nil {
-			securityContext.RunAsGroup = &gid
-			hasSecurityContext = true
+	if uid, gid := resolveUser(c, name, opts); uid != nil || gid != nil {
+		securityContext.RunAsUser = uid
+		securityContext.RunAsGroup = gid
+		if uid != nil && *uid != 0 {
+			nonRoot := true
+			securityContext.RunAsNonRoot = &nonRoot
 		}
+		hasSecurityContext = true
 	}
 	if c.Container.ReadOnly {
 		ro := true
@@ -427,6 +641,10 @@ func createContainerSpec(c *quadlet.ContainerUnit, name string, volumeRegistry m
 		hasSecurityContext = true
 	}
 
+	if applySecurityOptions(c, name, securityContext) {
+		hasSecurityContext = true
+	}
+
 	var sc *corev1.SecurityContext
 	if hasSecurityContext {
 		sc = securityContext
@@ -434,100 +652,80 @@ func createContainerSpec(c *quadlet.ContainerUnit, name string, volumeRegistry m
 
 	container := &corev1.Container{
 		Name:            name,
-		Image:           c.Container.Image,
+		Image:           image,
 		Command:         command,
 		Args:            args,
 		Env:             env,
-		Ports:           containerPorts,
+		Ports:           ports,
 		WorkingDir:      c.Container.WorkingDir,
 		VolumeMounts:    volumeMounts,
-		LivenessProbe:   livenessProbe,
+		LivenessProbe:   probes.Liveness,
+		ReadinessProbe:  probes.Readiness,
+		StartupProbe:    probes.Startup,
 		Resources:       resources,
 		SecurityContext: sc,
 	}
+	if err := pullPolicy(c, name, container); err != nil {
+		return nil, nil, nil, fmt.Errorf("container %s: %w", name, err)
+	}
+	runtimeVolumes, err := applyRuntimeOptions(c, name, container)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("container %s: %w", name, err)
+	}
+	volumes = append(volumes, runtimeVolumes...)
+	volumes = append(volumes, applyDevices(c, name, container, opts)...)
 
-	return container, volumes, servicePorts, nil
+	return container, volumes, published, nil
 }
 
-func parsePortSpec(spec string, name string) (*corev1.ContainerPort, int, *corev1.ServicePort, error) {
-	parts := strings.Split(spec, ":")
-	var containerPortStr, hostPortStr string
-
-	if len(parts) == 1 {
-		containerPortStr = parts[0]
-		hostPortStr = parts[0]
-	} else if len(parts) == 2 {
-		hostPortStr = parts[0]
-		containerPortStr = parts[1]
-		if hostPortStr == "" {
-			hostPortStr = containerPortStr
+func hasContainerPort(c *corev1.Container, port int32, protocol corev1.Protocol) bool {
+	for _, cp := range c.Ports {
+		if cp.ContainerPort == port && cp.Protocol == protocol {
+			return true
 		}
-	} else {
-		containerPortStr = parts[len(parts)-1]
-		hostPortStr = parts[len(parts)-2]
 	}
+	return false
+}
 
-	cPort, err := strconv.Atoi(containerPortStr)
-	if err != nil {
-		return nil, 0, nil, err
-	}
+// volumeOptions are the mount options Podman accepts after the destination
+// of a Volume= entry.
+var volumeOptions = map[string]bool{
+	"ro": true, "rw": true, "z": true, "Z": true, "U": true, "O": true,
+	"copy": true, "nocopy": true, "bind": true, "rbind": true,
+	"shared": true, "rshared": true, "slave": true, "rslave": true, "private": true, "rprivate": true,
+	"exec": true, "noexec": true, "dev": true, "nodev": true, "suid": true, "nosuid": true,
+	"idmap": true, "noatime": true,
+}
 
-	hPort, err := strconv.Atoi(hostPortStr)
-	if err != nil {
-		hPort = cPort
+// splitVolumeSpec splits a Volume= entry, [source:]dest[:options], into its
+// parts.
+func splitVolumeSpec(spec string) (source string, dest string, options []string) {
+	parts := strings.Split(spec, ":")
+	if len(parts) == 1 {
+		return "", parts[0], nil
 	}
 
-	if cPort > 65535 || cPort < 0 {
-		return nil, 0, nil, fmt.Errorf("container port %d out of valid range (0-65535)", cPort)
+	last := parts[len(parts)-1]
+	isOption := strings.Contains(last, ",")
+	for _, opt := range strings.Split(last, ",") {
+		name, _, _ := strings.Cut(opt, "=")
+		isOption = isOption || volumeOptions[name]
 	}
-	if hPort > 65535 || hPort < 0 {
-		return nil, 0, nil, fmt.Errorf("host port %d out of valid range (0-65535)", hPort)
+	if !isOption {
+		return strings.Join(parts[:len(parts)-1], ":"), last, nil
 	}
 
-	cp := &corev1.ContainerPort{
-		Name:          name,
-		ContainerPort: int32(cPort), // #nosec G109
-		Protocol:      corev1.ProtocolTCP,
+	options = strings.Split(last, ",")
+	dest = parts[len(parts)-2]
+	if len(parts) > 2 {
+		source = strings.Join(parts[:len(parts)-2], ":")
 	}
-
-	sp := &corev1.ServicePort{
-		Name:       name,
-		Port:       int32(hPort), // #nosec G109
-		TargetPort: intstr.FromInt(cPort),
-		Protocol:   corev1.ProtocolTCP,
-	}
-
-	return cp, hPort, sp, nil
+	return source, dest, options
 }
 
 func parseVolumeSpec(spec string, name string, volumeRegistry map[string]*quadlet.VolumeUnit) (*corev1.Volume, *corev1.VolumeMount, error) {
-	parts := strings.Split(spec, ":")
-	var source, dest string
-	var readOnly bool
-
-	if len(parts) == 1 {
-		dest = parts[0]
-		source = ""
-	} else {
-		last := parts[len(parts)-1]
-		isOption := false
-		if strings.Contains(last, ",") || last == "ro" || last == "rw" || last == "z" || last == "Z" {
-			isOption = true
-			if last == "ro" || strings.Contains(last, "ro,") || strings.Contains(last, ",ro") {
-				readOnly = true
-			}
-		}
-
-		if isOption {
-			dest = parts[len(parts)-2]
-			if len(parts) > 2 {
-				source = strings.Join(parts[:len(parts)-2], ":")
-			}
-		} else {
-			dest = parts[len(parts)-1]
-			source = strings.Join(parts[:len(parts)-1], ":")
-		}
-	}
+	source, dest, options := splitVolumeSpec(spec)
+	readOnly := slices.Contains(options, "ro")
 
 	vm := &corev1.VolumeMount{
 		Name:      name,
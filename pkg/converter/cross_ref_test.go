@@ -36,7 +36,7 @@ Volume=my-data.volume:/data
 	qContainer := quadlet.LoadContainer(cUnit)
 
 	// 3. Convert
-	objs, err := ConvertContainer(qContainer, "app", registry)
+	objs, err := ConvertContainer(qContainer, "app", registry, nil)
 	if err != nil {
 		t.Fatalf("ConvertContainer failed: %v", err)
 	}
@@ -94,7 +94,7 @@ Volume=pod-vol.volume:/data
 	qPod := quadlet.LoadPod(pUnit)
 
 	// 3. Convert
-	objs, err := ConvertPod(qPod, nil, nil, "my-pod", registry)
+	objs, err := ConvertPod(qPod, nil, nil, "my-pod", registry, nil)
 	if err != nil {
 		t.Fatalf("ConvertPod failed: %v", err)
 	}
@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ParseDeviceResource parses a --device-resource entry, <path>=<resource>,
+// mapping a host device (or a directory of devices) to the extended resource
+// of the device plugin exposing it.
+func ParseDeviceResource(s string) (path string, resourceName string, err error) {
+	path, resourceName, ok := strings.Cut(s, "=")
+	if !ok || !strings.HasPrefix(path, "/") || resourceName == "" {
+		return "", "", fmt.Errorf("invalid device resource %q: expected <path>=<resource>", s)
+	}
+	return strings.TrimSuffix(path, "/"), resourceName, nil
+}
+
+// isCDIDevice reports whether an AddDevice= entry is a fully-qualified CDI
+// device name, vendor.com/class=name.
+func isCDIDevice(device string) bool {
+	kind, name, ok := strings.Cut(device, "=")
+	return ok && name != "" && !strings.HasPrefix(kind, "/") && strings.Contains(kind, "/")
+}
+
+// deviceResource returns the extended resource mapped to a host device path
+// in opts.DeviceResources, matching the path itself or a parent directory.
+func deviceResource(path string, opts Options) (string, bool) {
+	for p := path; len(p) > 1 && strings.HasPrefix(p, "/"); p = p[:max(strings.LastIndex(p, "/"), 1)] {
+		if r, ok := opts.DeviceResources[p]; ok {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// applyDevices maps AddDevice= entries onto the container. CDI devices and
+// devices listed in opts.DeviceResources become extended resource limits;
+// other device nodes are mounted as CharDevice hostPath volumes, which only
+// work in privileged containers. It returns the volumes the container needs.
+func applyDevices(c *quadlet.ContainerUnit, name string, container *corev1.Container, opts Options) []corev1.Volume {
+	counts := make(map[string]int64)
+	var volumes []corev1.Volume
+	for i, device := range c.Container.AddDevice {
+		if isCDIDevice(device) {
+			kind, id, _ := strings.Cut(device, "=")
+			if id == "all" {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: AddDevice=%s requests all devices; Kubernetes needs a count, requesting 1 %s.\n", sanitize(name), sanitize(device), sanitize(kind))
+			}
+			counts[kind]++
+			continue
+		}
+
+		host, rest, _ := strings.Cut(device, ":")
+		target, _, _ := strings.Cut(rest, ":")
+		if target == "" || !strings.HasPrefix(target, "/") {
+			target = host
+		}
+		if r, ok := deviceResource(host, opts); ok {
+			counts[r]++
+			continue
+		}
+
+		charDevice := corev1.HostPathCharDev
+		volName := fmt.Sprintf("dev-%d", i)
+		volumes = append(volumes, corev1.Volume{
+			Name: volName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: host, Type: &charDevice},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volName, MountPath: target})
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: AddDevice=%s mounted as a hostPath; the device cgroup only permits access with privileged: true, or map it to a device plugin resource with --device-resource.\n", sanitize(name), sanitize(device))
+	}
+
+	if len(counts) == 0 {
+		return volumes
+	}
+	kinds := make([]string, 0, len(counts))
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	if container.Resources.Limits == nil {
+		container.Resources.Limits = corev1.ResourceList{}
+	}
+	for _, k := range kinds {
+		container.Resources.Limits[corev1.ResourceName(k)] = *resource.NewQuantity(counts[k], resource.DecimalSI)
+	}
+	return volumes
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertContainer_Devices(t *testing.T) {
+	input := `
+[Container]
+Image=app
+Device=nvidia.com/gpu=0
+AddDevice=nvidia.com/gpu=1
+AddDevice=/dev/dri/renderD128
+AddDevice=/dev/ttyUSB0:/dev/ttyACM0:rw
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	opts := &Options{DeviceResources: map[string]string{"/dev/dri": "gpu.intel.com/i915"}}
+	objs, err := ConvertContainer(qContainer, "app", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	limits := spec.Containers[0].Resources.Limits
+
+	if q := limits["nvidia.com/gpu"]; q.Value() != 2 {
+		t.Errorf("Expected 2 nvidia.com/gpu, got %s", q.String())
+	}
+	if q := limits["gpu.intel.com/i915"]; q.Value() != 1 {
+		t.Errorf("Expected 1 gpu.intel.com/i915 from the device table, got %s", q.String())
+	}
+
+	if len(spec.Volumes) != 1 {
+		t.Fatalf("Expected one hostPath device volume, got %+v", spec.Volumes)
+	}
+	hp := spec.Volumes[0].HostPath
+	if hp == nil || hp.Path != "/dev/ttyUSB0" || hp.Type == nil || *hp.Type != corev1.HostPathCharDev {
+		t.Errorf("Unexpected device volume: %+v", spec.Volumes[0])
+	}
+	if m := spec.Containers[0].VolumeMounts; len(m) != 1 || m[0].MountPath != "/dev/ttyACM0" {
+		t.Errorf("Expected device mounted at /dev/ttyACM0, got %+v", m)
+	}
+}
+
+func TestParseDeviceResource(t *testing.T) {
+	path, res, err := ParseDeviceResource("/dev/dri/=gpu.intel.com/i915")
+	if err != nil || path != "/dev/dri" || res != "gpu.intel.com/i915" {
+		t.Errorf("Unexpected result: %q %q %v", path, res, err)
+	}
+	for _, in := range []string{"dev/dri=x", "/dev/dri", "/dev/dri="} {
+		if _, _, err := ParseDeviceResource(in); err == nil {
+			t.Errorf("ParseDeviceResource(%q) should fail", in)
+		}
+	}
+}
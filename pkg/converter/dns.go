@@ -0,0 +1,173 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxNameservers is the Kubernetes limit on nameservers in a pod's
+// resolv.conf, including the cluster resolver under dnsPolicy ClusterFirst.
+const maxNameservers = 3
+
+// dnsSettings collects the name resolution keys of a unit. Pods merge the
+// settings of the pod and each of its containers.
+type dnsSettings struct {
+	AddHost   []string
+	DNS       []string
+	DNSSearch []string
+	DNSOption []string
+}
+
+func (d *dnsSettings) addContainer(c *quadlet.ContainerUnit) {
+	d.AddHost = append(d.AddHost, c.Container.AddHost...)
+	d.DNS = append(d.DNS, c.Container.DNS...)
+	d.DNSSearch = append(d.DNSSearch, c.Container.DNSSearch...)
+	d.DNSOption = append(d.DNSOption, c.Container.DNSOption...)
+}
+
+func (d *dnsSettings) addPod(p *quadlet.PodUnit) {
+	d.AddHost = append(d.AddHost, p.Pod.AddHost...)
+	d.DNS = append(d.DNS, p.Pod.DNS...)
+	d.DNSSearch = append(d.DNSSearch, p.Pod.DNSSearch...)
+	d.DNSOption = append(d.DNSOption, p.Pod.DNSOption...)
+}
+
+// applyDNS maps AddHost to hostAliases and DNS, DNSSearch and DNSOption to
+// dnsConfig. Resolvers set on the unit replace cluster DNS (dnsPolicy: None),
+// as they replace the resolver in Podman; DNS= of joined networks only adds
+// upstream resolvers after cluster DNS.
+func applyDNS(unit string, spec *corev1.PodSpec, d dnsSettings, joined []joinedNetwork, opts Options) error {
+	aliases, err := hostAliases(d.AddHost)
+	if err != nil {
+		return fmt.Errorf("%s: %w", unit, err)
+	}
+	spec.HostAliases = aliases
+
+	servers, err := nameservers(unit, d.DNS, maxNameservers)
+	if err != nil {
+		return fmt.Errorf("%s: %w", unit, err)
+	}
+	custom := len(servers) > 0
+	if !custom {
+		var inherited []string
+		for _, j := range joined {
+			inherited = append(inherited, opts.Networks[j.Name].Network.DNS...)
+		}
+		// The cluster resolver takes one of the slots.
+		if servers, err = nameservers(unit, inherited, maxNameservers-1); err != nil {
+			return fmt.Errorf("%s: network DNS: %w", unit, err)
+		}
+	}
+
+	var searches []string
+	seen := make(map[string]bool)
+	for _, s := range d.DNSSearch {
+		// "." clears the search list in Podman; there is nothing to add.
+		if s != "." && !seen[s] {
+			seen[s] = true
+			searches = append(searches, s)
+		}
+	}
+
+	var options []corev1.PodDNSConfigOption
+	values := make(map[string]string)
+	for _, o := range d.DNSOption {
+		name, value, hasValue := strings.Cut(o, ":")
+		if prev, ok := values[name]; ok {
+			if prev != value {
+				return fmt.Errorf("%s: conflicting DNSOption values for %s: %q and %q", unit, name, prev, value)
+			}
+			continue
+		}
+		values[name] = value
+		opt := corev1.PodDNSConfigOption{Name: name}
+		if hasValue {
+			opt.Value = &value
+		}
+		options = append(options, opt)
+	}
+
+	if len(servers) == 0 && len(searches) == 0 && len(options) == 0 {
+		return nil
+	}
+	spec.DNSConfig = &corev1.PodDNSConfig{
+		Nameservers: servers,
+		Searches:    searches,
+		Options:     options,
+	}
+	if custom {
+		spec.DNSPolicy = corev1.DNSNone
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: DNS= replaces cluster DNS (dnsPolicy: None); Service names only resolve if the given servers forward cluster.local.\n", sanitize(unit))
+	}
+	return nil
+}
+
+// nameservers validates and deduplicates DNS= addresses. DNS=none, which
+// stops Podman from configuring resolv.conf, has no equivalent.
+func nameservers(unit string, values []string, limit int) ([]string, error) {
+	var servers []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if v == "none" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: DNS=none has no Kubernetes equivalent; ignoring.\n", sanitize(unit))
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DNS server %q: expected an IP address", v)
+		}
+		if s := addr.String(); !seen[s] {
+			seen[s] = true
+			servers = append(servers, s)
+		}
+	}
+	if len(servers) > limit {
+		return nil, fmt.Errorf("%d DNS servers given, Kubernetes allows at most %d here", len(servers), limit)
+	}
+	return servers, nil
+}
+
+// hostAliases parses AddHost entries, "host[;host...]:ip" or "host=ip", into
+// hostAliases grouped by IP in order of appearance.
+func hostAliases(values []string) ([]corev1.HostAlias, error) {
+	var aliases []corev1.HostAlias
+	index := make(map[string]int)
+	for _, v := range values {
+		hosts, ip, ok := strings.Cut(v, "=")
+		if !ok {
+			hosts, ip, ok = strings.Cut(v, ":")
+		}
+		if !ok || hosts == "" {
+			return nil, fmt.Errorf("invalid AddHost %q: expected host:ip", v)
+		}
+		if ip == "host-gateway" {
+			return nil, fmt.Errorf("AddHost %q: host-gateway has no Kubernetes equivalent", v)
+		}
+		addr, err := netip.ParseAddr(strings.Trim(ip, "[]"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AddHost %q: %q is not an IP address", v, ip)
+		}
+
+		key := addr.String()
+		i, found := index[key]
+		if !found {
+			i = len(aliases)
+			index[key] = i
+			aliases = append(aliases, corev1.HostAlias{IP: key})
+		}
+		for _, h := range strings.Split(hosts, ";") {
+			if h != "" && !slices.Contains(aliases[i].Hostnames, h) {
+				aliases[i].Hostnames = append(aliases[i].Hostnames, h)
+			}
+		}
+	}
+	return aliases, nil
+}
@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertContainer_DNS(t *testing.T) {
+	input := `
+[Container]
+Image=app
+AddHost=legacy-db:10.0.0.5
+AddHost=legacy-cache;cache.local:10.0.0.5
+AddHost=ldap=10.0.0.9
+DNS=10.0.0.53
+DNSSearch=corp.example.com
+DNSOption=ndots:2
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+
+	if len(spec.HostAliases) != 2 {
+		t.Fatalf("Expected 2 hostAliases, got %+v", spec.HostAliases)
+	}
+	if spec.HostAliases[0].IP != "10.0.0.5" || strings.Join(spec.HostAliases[0].Hostnames, ",") != "legacy-db,legacy-cache,cache.local" {
+		t.Errorf("Unexpected hostAlias: %+v", spec.HostAliases[0])
+	}
+	if spec.HostAliases[1].IP != "10.0.0.9" || spec.HostAliases[1].Hostnames[0] != "ldap" {
+		t.Errorf("Unexpected hostAlias: %+v", spec.HostAliases[1])
+	}
+
+	if spec.DNSPolicy != corev1.DNSNone {
+		t.Errorf("Expected dnsPolicy None, got %q", spec.DNSPolicy)
+	}
+	cfg := spec.DNSConfig
+	if cfg == nil || len(cfg.Nameservers) != 1 || cfg.Nameservers[0] != "10.0.0.53" {
+		t.Fatalf("Unexpected dnsConfig: %+v", cfg)
+	}
+	if len(cfg.Searches) != 1 || cfg.Searches[0] != "corp.example.com" {
+		t.Errorf("Unexpected searches: %v", cfg.Searches)
+	}
+	if len(cfg.Options) != 1 || cfg.Options[0].Name != "ndots" || *cfg.Options[0].Value != "2" {
+		t.Errorf("Unexpected options: %+v", cfg.Options)
+	}
+}
+
+func TestConvertPod_DNSMerge(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{
+		"corp": "[Network]\nDNS=10.1.0.53\n",
+	})
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\nNetwork=corp.network\nDNSSearch=corp.example.com\n"))
+	qPod := quadlet.LoadPod(pUnit)
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=a\nAddHost=db:10.0.0.5\nDNSOption=ndots:2\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=b\nAddHost=cache:10.0.0.6\nDNSSearch=corp.example.com\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2)}
+
+	objs, err := ConvertPod(qPod, containers, []string{"a", "b"}, "app", nil, &Options{Networks: networks})
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+
+	if len(spec.HostAliases) != 2 {
+		t.Errorf("Expected hostAliases of both containers, got %+v", spec.HostAliases)
+	}
+	// Network resolvers are added after cluster DNS.
+	if spec.DNSPolicy != "" {
+		t.Errorf("Expected default dnsPolicy, got %q", spec.DNSPolicy)
+	}
+	cfg := spec.DNSConfig
+	if cfg == nil || len(cfg.Nameservers) != 1 || cfg.Nameservers[0] != "10.1.0.53" {
+		t.Fatalf("Unexpected dnsConfig: %+v", cfg)
+	}
+	if len(cfg.Searches) != 1 || len(cfg.Options) != 1 {
+		t.Errorf("Expected merged searches and options, got %+v", cfg)
+	}
+}
+
+func TestConvertPod_DNSOptionConflict(t *testing.T) {
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\nDNSOption=ndots:1\n"))
+	cUnit, _ := parser.Parse(strings.NewReader("[Container]\nImage=a\nDNSOption=ndots:5\n"))
+
+	_, err := ConvertPod(quadlet.LoadPod(pUnit), []*quadlet.ContainerUnit{quadlet.LoadContainer(cUnit)}, []string{"a"}, "app", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "conflicting DNSOption") {
+		t.Errorf("Expected DNSOption conflict error, got %v", err)
+	}
+}
@@ -51,7 +51,7 @@ PublishPort=8080:90
 	unit, _ := parser.Parse(reader)
 	qContainer := quadlet.LoadContainer(unit)
 
-	_, err := ConvertContainer(qContainer, "app", nil)
+	_, err := ConvertContainer(qContainer, "app", nil, nil)
 	if err == nil {
 		t.Fatal("Expected error for duplicate ports, got nil")
 	}
@@ -71,7 +71,7 @@ PublishPort=8080:81
 	unit, _ := parser.Parse(reader)
 	qPod := quadlet.LoadPod(unit)
 
-	_, err := ConvertPod(qPod, nil, nil, "pod", nil)
+	_, err := ConvertPod(qPod, nil, nil, "pod", nil, nil)
 	if err == nil {
 		t.Fatal("Expected error for duplicate ports, got nil")
 	}
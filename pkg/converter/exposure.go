@@ -0,0 +1,422 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Per-unit HTTP routing settings.
+const (
+	SettingHost      = "kuadlet.io/host"
+	SettingPath      = "kuadlet.io/path"
+	SettingPort      = "kuadlet.io/port"
+	SettingTLSSecret = "kuadlet.io/tls-secret"
+)
+
+// Exposure selects which routing resources are generated for HTTP services.
+type Exposure string
+
+const (
+	ExposureNone      Exposure = ""
+	ExposureIngress   Exposure = "Ingress"
+	ExposureHTTPRoute Exposure = "HTTPRoute"
+)
+
+// ParseExposure parses an exposure name case-insensitively. "none" and the
+// empty string disable route generation.
+func ParseExposure(s string) (Exposure, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return ExposureNone, nil
+	case "ingress":
+		return ExposureIngress, nil
+	case "httproute", "gateway":
+		return ExposureHTTPRoute, nil
+	}
+	return "", fmt.Errorf("unknown exposure %q (expected none, ingress or httproute)", s)
+}
+
+// httpRoute is a host/path rule routed to a container port. Port 0 means the
+// first port of the unit's Service.
+type httpRoute struct {
+	Hosts     []string
+	Path      string
+	Port      int32
+	TLS       bool
+	TLSSecret string
+	Source    string // label or annotation the route was derived from
+}
+
+// collectRoutes derives HTTP routes from a unit's labels and annotations: the
+// kuadlet.io/host setting first, then Traefik and Caddy label conventions.
+func collectRoutes(unit string, labels map[string]string, annotations map[string]string) ([]httpRoute, error) {
+	settings := make(map[string]string, len(labels)+len(annotations))
+	for k, v := range labels {
+		settings[k] = v
+	}
+	for k, v := range annotations {
+		settings[k] = v
+	}
+
+	var routes []httpRoute
+
+	if host := settings[SettingHost]; host != "" {
+		r := httpRoute{
+			Hosts:     strings.Fields(strings.ReplaceAll(host, ",", " ")),
+			Path:      settings[SettingPath],
+			TLSSecret: settings[SettingTLSSecret],
+			Source:    SettingHost,
+		}
+		r.TLS = r.TLSSecret != ""
+		if p := settings[SettingPort]; p != "" {
+			port, err := parsePortNumber(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", SettingPort, err)
+			}
+			r.Port = port
+		}
+		routes = append(routes, r)
+	}
+
+	routes = append(routes, traefikRoutes(unit, settings)...)
+	routes = append(routes, caddyRoutes(unit, settings)...)
+
+	for i := range routes {
+		if routes[i].Path == "" {
+			routes[i].Path = "/"
+		}
+		if !strings.HasPrefix(routes[i].Path, "/") {
+			return nil, fmt.Errorf("route from %s: path %q must start with '/'", routes[i].Source, routes[i].Path)
+		}
+	}
+	return routes, nil
+}
+
+var (
+	traefikMatcherRe = regexp.MustCompile(`(\w+)\(([^)]*)\)`)
+	quotedRe         = regexp.MustCompile("`([^`]*)`|\"([^\"]*)\"")
+)
+
+// traefikRoutes translates traefik.http.routers.<name>.rule labels.
+func traefikRoutes(unit string, settings map[string]string) []httpRoute {
+	if v, ok := settings["traefik.enable"]; ok && !strings.EqualFold(v, "true") {
+		return nil
+	}
+
+	// Ports declared per Traefik service.
+	servicePorts := make(map[string]int32)
+	for k, v := range settings {
+		if svc, ok := strings.CutPrefix(k, "traefik.http.services."); ok {
+			if svc, ok = strings.CutSuffix(svc, ".loadbalancer.server.port"); ok {
+				if port, err := parsePortNumber(v); err == nil {
+					servicePorts[svc] = port
+				}
+			}
+		}
+	}
+
+	var routers []string
+	for k := range settings {
+		if router, ok := strings.CutPrefix(k, "traefik.http.routers."); ok {
+			if router, ok = strings.CutSuffix(router, ".rule"); ok {
+				routers = append(routers, router)
+			}
+		}
+	}
+	sort.Strings(routers)
+
+	var routes []httpRoute
+	for _, router := range routers {
+		prefix := "traefik.http.routers." + router
+		source := prefix + ".rule"
+		r := httpRoute{Source: source}
+
+		supported := true
+		for _, m := range traefikMatcherRe.FindAllStringSubmatch(settings[source], -1) {
+			var args []string
+			for _, q := range quotedRe.FindAllStringSubmatch(m[2], -1) {
+				args = append(args, q[1]+q[2])
+			}
+			switch m[1] {
+			case "Host":
+				r.Hosts = append(r.Hosts, args...)
+			case "PathPrefix", "Path":
+				if len(args) > 0 {
+					r.Path = args[0]
+				}
+			default:
+				supported = false
+			}
+		}
+		if !supported || len(r.Hosts) == 0 {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Traefik rule %s=%s cannot be translated (only Host, Path and PathPrefix are supported); skipping.\n", sanitize(unit), sanitize(source), sanitize(settings[source]))
+			continue
+		}
+
+		if svc, ok := settings[prefix+".service"]; ok {
+			r.Port = servicePorts[svc]
+		} else if len(servicePorts) == 1 {
+			for _, p := range servicePorts {
+				r.Port = p
+			}
+		}
+
+		if strings.EqualFold(settings[prefix+".tls"], "true") || settings[prefix+".tls.certresolver"] != "" {
+			r.TLS = true
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+var caddyUpstreamsRe = regexp.MustCompile(`\{\{\s*upstreams\s+(?:https?\s+)?(\d+)\s*\}\}`)
+
+// caddyRoutes translates caddy-docker-proxy labels (caddy, caddy_0, ...).
+func caddyRoutes(unit string, settings map[string]string) []httpRoute {
+	var sites []string
+	for k := range settings {
+		if k == "caddy" || (strings.HasPrefix(k, "caddy_") && !strings.Contains(k, ".")) {
+			sites = append(sites, k)
+		}
+	}
+	sort.Strings(sites)
+
+	var routes []httpRoute
+	for _, site := range sites {
+		r := httpRoute{Source: site, TLS: true}
+		for _, addr := range strings.Fields(strings.ReplaceAll(settings[site], ",", " ")) {
+			if rest, ok := strings.CutPrefix(addr, "http://"); ok {
+				r.TLS = false
+				addr = rest
+			}
+			addr = strings.TrimPrefix(addr, "https://")
+			host, path, _ := strings.Cut(addr, "/")
+			if path != "" {
+				r.Path = "/" + strings.TrimSuffix(path, "*")
+			}
+			if h, _, ok := strings.Cut(host, ":"); ok {
+				host = h
+			}
+			r.Hosts = append(r.Hosts, host)
+		}
+		if len(r.Hosts) == 0 {
+			continue
+		}
+
+		if m := caddyUpstreamsRe.FindStringSubmatch(settings[site+".reverse_proxy"]); m != nil {
+			if port, err := parsePortNumber(m[1]); err == nil {
+				r.Port = port
+			}
+		} else if _, ok := settings[site+".reverse_proxy"]; !ok {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Caddy site %s has no reverse_proxy label; routing to the first Service port.\n", sanitize(unit), sanitize(site))
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// bindRoutes resolves the Service port of each route, adding a Service port
+// for container ports that are routed to but not published.
+func bindRoutes(routes []httpRoute, published *publishedPorts) ([]int32, error) {
+	ports := make([]int32, len(routes))
+	for i, r := range routes {
+		if r.Port == 0 {
+			// HTTP is served over TCP; skip published UDP ports.
+			idx := slices.IndexFunc(published.Service, func(sp corev1.ServicePort) bool { return sp.Protocol == corev1.ProtocolTCP })
+			if idx < 0 {
+				return nil, fmt.Errorf("route from %s has no port and the unit publishes no TCP port; set %s", r.Source, SettingPort)
+			}
+			ports[i] = published.Service[idx].Port
+			continue
+		}
+
+		found := false
+		for _, sp := range published.Service {
+			if sp.TargetPort.IntVal == r.Port && sp.Protocol == corev1.ProtocolTCP {
+				ports[i] = sp.Port
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		for _, sp := range published.Service {
+			if sp.Port == r.Port && sp.Protocol == corev1.ProtocolTCP {
+				return nil, fmt.Errorf("route from %s targets container port %d, which conflicts with published port %d", r.Source, r.Port, sp.Port)
+			}
+		}
+		name := fmt.Sprintf("http-%d", r.Port)
+		published.Service = append(published.Service, corev1.ServicePort{
+			Name:       name,
+			Port:       r.Port,
+			TargetPort: intstr.FromInt32(r.Port),
+			Protocol:   corev1.ProtocolTCP,
+		})
+		ports[i] = r.Port
+	}
+	return ports, nil
+}
+
+// exposeRoutes resolves the routes of a unit against its published ports and
+// returns the routing objects to emit, if exposure is enabled.
+func exposeRoutes(name string, labels map[string]string, routes []httpRoute, published *publishedPorts, opts Options) ([]runtime.Object, error) {
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	if opts.Exposure == ExposureNone {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s defines HTTP routes (%s) but no exposure is selected; no Ingress or HTTPRoute is generated.\n", sanitize(name), sanitize(routes[0].Source))
+		return nil, nil
+	}
+	ports, err := bindRoutes(routes, published)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return newRouteObjects(name, labels, routes, ports, opts), nil
+}
+
+// newRouteObjects builds the Ingress or HTTPRoutes for a unit's routes. ports
+// holds the Service port of each route as returned by bindRoutes.
+func newRouteObjects(name string, labels map[string]string, routes []httpRoute, ports []int32, opts Options) []runtime.Object {
+	switch opts.Exposure {
+	case ExposureIngress:
+		return []runtime.Object{newIngress(name, labels, routes, ports, opts)}
+	case ExposureHTTPRoute:
+		var objects []runtime.Object
+		for i, r := range routes {
+			routeName := name
+			if len(routes) > 1 {
+				routeName = fmt.Sprintf("%s-%d", name, i)
+			}
+			if r.TLS {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: route from %s requests TLS; configure a certificate on the Gateway listener for %s.\n", sanitize(name), sanitize(r.Source), strings.Join(r.Hosts, ", "))
+			}
+			objects = append(objects, newHTTPRoute(routeName, name, labels, r, ports[i], opts))
+		}
+		return objects
+	}
+	return nil
+}
+
+func newIngress(name string, labels map[string]string, routes []httpRoute, ports []int32, opts Options) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+	if opts.IngressClassName != "" {
+		className := opts.IngressClassName
+		ingress.Spec.IngressClassName = &className
+	}
+
+	for i, r := range routes {
+		path := networkingv1.HTTPIngressPath{
+			Path:     r.Path,
+			PathType: &pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: name,
+					Port: networkingv1.ServiceBackendPort{Number: ports[i]},
+				},
+			},
+		}
+		for _, host := range r.Hosts {
+			ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{path},
+					},
+				},
+			})
+		}
+		if r.TLS {
+			secret := r.TLSSecret
+			if secret == "" {
+				secret = fmt.Sprintf("%s-tls", name)
+			}
+			ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1.IngressTLS{
+				Hosts:      r.Hosts,
+				SecretName: secret,
+			})
+		}
+	}
+	return ingress
+}
+
+// newHTTPRoute builds a gateway.networking.k8s.io/v1 HTTPRoute. The Gateway
+// API types are not vendored, so the object is built unstructured.
+func newHTTPRoute(routeName string, serviceName string, labels map[string]string, r httpRoute, port int32, opts Options) *unstructured.Unstructured {
+	parentRef := map[string]interface{}{
+		"name": opts.GatewayName,
+	}
+	if opts.GatewayNamespace != "" {
+		parentRef["namespace"] = opts.GatewayNamespace
+	}
+
+	hostnames := make([]interface{}, len(r.Hosts))
+	for i, h := range r.Hosts {
+		hostnames[i] = h
+	}
+
+	metadata := map[string]interface{}{
+		"name": routeName,
+	}
+	if len(labels) > 0 {
+		l := make(map[string]interface{}, len(labels))
+		for k, v := range labels {
+			l[k] = v
+		}
+		metadata["labels"] = l
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   metadata,
+		"spec": map[string]interface{}{
+			"parentRefs": []interface{}{parentRef},
+			"hostnames":  hostnames,
+			"rules": []interface{}{
+				map[string]interface{}{
+					"matches": []interface{}{
+						map[string]interface{}{
+							"path": map[string]interface{}{
+								"type":  "PathPrefix",
+								"value": r.Path,
+							},
+						},
+					},
+					"backendRefs": []interface{}{
+						map[string]interface{}{
+							"name": serviceName,
+							"port": int64(port),
+						},
+					},
+				},
+			},
+		},
+	}}
+}
@@ -0,0 +1,183 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConvertContainer_TraefikIngress(t *testing.T) {
+	input := "\n[Container]\nImage=app\n" +
+		"Label=traefik.http.routers.app.rule=Host(`app.example.com`) && PathPrefix(`/api`)\n" +
+		"Label=traefik.http.routers.app.tls.certresolver=le\n" +
+		"Label=traefik.http.services.app.loadbalancer.server.port=8080\n"
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, &Options{Exposure: ExposureIngress, IngressClassName: "nginx"})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	var ingress *networkingv1.Ingress
+	for _, obj := range objs {
+		if i, ok := obj.(*networkingv1.Ingress); ok {
+			ingress = i
+		}
+	}
+	if ingress == nil {
+		t.Fatal("Ingress not found")
+	}
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("Expected ingressClassName nginx, got %v", ingress.Spec.IngressClassName)
+	}
+	if len(ingress.Spec.Rules) != 1 || ingress.Spec.Rules[0].Host != "app.example.com" {
+		t.Fatalf("Unexpected rules: %+v", ingress.Spec.Rules)
+	}
+	path := ingress.Spec.Rules[0].HTTP.Paths[0]
+	if path.Path != "/api" {
+		t.Errorf("Expected path /api, got %s", path.Path)
+	}
+	if path.Backend.Service.Name != "app" || path.Backend.Service.Port.Number != 8080 {
+		t.Errorf("Unexpected backend: %+v", path.Backend.Service)
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "app-tls" {
+		t.Errorf("Expected TLS with secret app-tls, got %+v", ingress.Spec.TLS)
+	}
+
+	// The routed port is not published, so a Service port is added for it.
+	service := findService(objs)
+	if service == nil {
+		t.Fatal("Service not found")
+	}
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != 8080 {
+		t.Errorf("Expected Service port 8080, got %+v", service.Spec.Ports)
+	}
+}
+
+func TestConvertPod_CaddyHTTPRoute(t *testing.T) {
+	podInput := `
+[Pod]
+PublishPort=8443:443
+`
+	containerInput := `
+[Container]
+Image=web
+Pod=site.pod
+Label=caddy=site.example.com
+Label=caddy.reverse_proxy={{upstreams 443}}
+`
+	pUnit, _ := parser.Parse(strings.NewReader(podInput))
+	qPod := quadlet.LoadPod(pUnit)
+	cUnit, _ := parser.Parse(strings.NewReader(containerInput))
+	qContainer := quadlet.LoadContainer(cUnit)
+
+	opts := &Options{Exposure: ExposureHTTPRoute, GatewayName: "public", GatewayNamespace: "infra"}
+	objs, err := ConvertPod(qPod, []*quadlet.ContainerUnit{qContainer}, []string{"web"}, "site", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+
+	var route *unstructured.Unstructured
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok && u.GetKind() == "HTTPRoute" {
+			route = u
+		}
+	}
+	if route == nil {
+		t.Fatal("HTTPRoute not found")
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != "site.example.com" {
+		t.Errorf("Unexpected hostnames: %v", hostnames)
+	}
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parents) != 1 || parents[0].(map[string]interface{})["namespace"] != "infra" {
+		t.Errorf("Unexpected parentRefs: %v", parents)
+	}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	backend := rules[0].(map[string]interface{})["backendRefs"].([]interface{})[0].(map[string]interface{})
+	// Container port 443 is published as 8443 on the pod Service.
+	if backend["name"] != "site" || backend["port"] != int64(8443) {
+		t.Errorf("Unexpected backendRef: %v", backend)
+	}
+}
+
+func TestConvertContainer_RouteWithoutPort(t *testing.T) {
+	input := `
+[Container]
+Image=app
+Annotation=kuadlet.io/host=app.example.com
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	_, err := ConvertContainer(qContainer, "app", nil, &Options{Exposure: ExposureIngress})
+	if err == nil {
+		t.Fatal("Expected error for route without port, got nil")
+	}
+	if !strings.Contains(err.Error(), SettingPort) {
+		t.Errorf("Expected error mentioning %s, got: %v", SettingPort, err)
+	}
+}
+
+func TestConvertPod_RoutedPortDeclared(t *testing.T) {
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=db\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=app\n" +
+		"Label=traefik.http.routers.app.rule=Host(`app.example.com`)\n" +
+		"Label=traefik.http.services.app.loadbalancer.server.port=8080\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2)}
+
+	objs, err := ConvertPod(quadlet.LoadPod(pUnit), containers, []string{"db", "app"}, "site", nil, &Options{Exposure: ExposureIngress})
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	if len(spec.Containers[0].Ports) != 0 {
+		t.Errorf("Expected no ports on db, got %+v", spec.Containers[0].Ports)
+	}
+	if ports := spec.Containers[1].Ports; len(ports) != 1 || ports[0].ContainerPort != 8080 {
+		t.Errorf("Expected the routed port 8080 declared on app, got %+v", ports)
+	}
+	if service := findService(objs); service == nil || len(service.Spec.Ports) != 1 || service.Spec.Ports[0].TargetPort.IntVal != 8080 {
+		t.Errorf("Expected a Service port targeting 8080, got %+v", service)
+	}
+}
+
+func TestConvertContainer_RouteSkipsUDPPort(t *testing.T) {
+	input := "[Container]\nImage=app\nPublishPort=5353:53/udp\nPublishPort=8080:80\nAnnotation=kuadlet.io/host=app.example.com\n"
+	unit, _ := parser.Parse(strings.NewReader(input))
+
+	objs, err := ConvertContainer(quadlet.LoadContainer(unit), "app", nil, &Options{Exposure: ExposureIngress})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	for _, obj := range objs {
+		if i, ok := obj.(*networkingv1.Ingress); ok {
+			if port := i.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number; port != 8080 {
+				t.Errorf("Expected the TCP port 8080 as backend, got %d", port)
+			}
+			return
+		}
+	}
+	t.Fatal("Ingress not found")
+}
+
+func TestConvertContainer_RouteWithOnlyUDPPorts(t *testing.T) {
+	input := "[Container]\nImage=app\nPublishPort=5353:53/udp\nAnnotation=kuadlet.io/host=app.example.com\n"
+	unit, _ := parser.Parse(strings.NewReader(input))
+
+	_, err := ConvertContainer(quadlet.LoadContainer(unit), "app", nil, &Options{Exposure: ExposureIngress})
+	if err == nil || !strings.Contains(err.Error(), "no TCP port") {
+		t.Errorf("Expected an error about missing TCP ports, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// containerProbes holds the probes derived from a unit's health settings.
+type containerProbes struct {
+	Liveness  *corev1.Probe
+	Readiness *corev1.Probe
+	Startup   *corev1.Probe
+}
+
+// createProbes maps Podman health checks onto Kubernetes probes:
+//
+//   - HealthCmd becomes the livenessProbe, unless HealthOnFailure=none.
+//   - HealthStartupCmd becomes the startupProbe.
+//   - Notify=healthy additionally turns HealthCmd into a readinessProbe.
+func createProbes(c *quadlet.ContainerUnit, name string, opts Options) (containerProbes, error) {
+	var probes containerProbes
+	safeName := sanitize(name)
+	cs := &c.Container
+
+	healthCmd := cs.HealthCmd
+	if healthCmd == "none" {
+		healthCmd = ""
+	}
+
+	if healthCmd != "" {
+		handler := healthHandler(healthCmd, "HealthCmd", name, opts)
+		onFailure := strings.ToLower(cs.HealthOnFailure)
+		switch onFailure {
+		case "", "kill", "restart":
+			probes.Liveness = newHealthProbe(handler, cs.HealthInterval, cs.HealthTimeout, cs.HealthStartPeriod, cs.HealthRetries)
+		case "stop":
+			probes.Liveness = newHealthProbe(handler, cs.HealthInterval, cs.HealthTimeout, cs.HealthStartPeriod, cs.HealthRetries)
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: HealthOnFailure=stop has no Kubernetes equivalent; the failing container will be restarted instead.\n", safeName)
+		case "none":
+			if !strings.EqualFold(cs.Notify, "healthy") {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: HealthOnFailure=none; HealthCmd only marks the container unhealthy and is not mapped to a livenessProbe.\n", safeName)
+			}
+		default:
+			return probes, fmt.Errorf("container %s: invalid HealthOnFailure %q (expected none, kill, restart or stop)", name, cs.HealthOnFailure)
+		}
+
+		if strings.EqualFold(cs.Notify, "healthy") {
+			probes.Readiness = newHealthProbe(handler, cs.HealthInterval, cs.HealthTimeout, cs.HealthStartPeriod, cs.HealthRetries)
+		}
+	} else if strings.EqualFold(cs.Notify, "healthy") {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: Notify=healthy without HealthCmd; no readinessProbe is generated.\n", safeName)
+	}
+
+	if cs.HealthStartupCmd != "" && cs.HealthStartupCmd != "none" {
+		if healthCmd == "" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: HealthStartupCmd without HealthCmd is ignored by Podman; generating a startupProbe anyway.\n", safeName)
+		}
+		handler := healthHandler(cs.HealthStartupCmd, "HealthStartupCmd", name, opts)
+		probes.Startup = newHealthProbe(handler, cs.HealthStartupInterval, cs.HealthStartupTimeout, "", cs.HealthStartupRetries)
+		if cs.HealthStartupSuccess > 1 {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: HealthStartupSuccess=%d cannot be mapped; Kubernetes requires startupProbe.successThreshold to be 1.\n", safeName, cs.HealthStartupSuccess)
+		}
+	}
+
+	return probes, nil
+}
+
+// newHealthProbe builds a probe with Podman health check timings.
+func newHealthProbe(handler corev1.ProbeHandler, interval string, timeout string, startPeriod string, retries int) *corev1.Probe {
+	probe := &corev1.Probe{
+		ProbeHandler: handler,
+	}
+
+	if s, ok := durationSeconds(interval); ok {
+		probe.PeriodSeconds = s
+	}
+	if s, ok := durationSeconds(timeout); ok {
+		probe.TimeoutSeconds = s
+	}
+	if s, ok := durationSeconds(startPeriod); ok {
+		probe.InitialDelaySeconds = s
+	}
+	if retries > 0 {
+		if retries > math.MaxInt32 {
+			probe.FailureThreshold = math.MaxInt32
+		} else {
+			probe.FailureThreshold = int32(retries)
+		}
+	}
+	return probe
+}
+
+// healthHandler returns the handler for a health command. With
+// opts.NativeProbes, requests made with curl, wget or nc against localhost
+// become native httpGet/tcpSocket probes, which also work in images without
+// those tools but reach the app on the pod IP rather than loopback. Anything
+// else runs as an exec probe: Podman treats a string HealthCmd as CMD-SHELL,
+// so it is wrapped in `sh -c`; splitting it into arguments would break
+// `curl ... || exit 1`.
+func healthHandler(cmd string, key string, name string, opts Options) corev1.ProbeHandler {
+	safeName := sanitize(name)
+	var handler *corev1.ProbeHandler
+	var head bool
+	var err error
+	if opts.NativeProbes {
+		handler, head, err = nativeHealthHandler(cmd)
+	}
+	switch {
+	case handler != nil && handler.HTTPGet != nil:
+		method := ""
+		if head {
+			method = ", sending GET instead of HEAD"
+		}
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s translated to an httpGet probe (%s port %s path %s%s); the kubelet probes the pod IP, so the app must not listen on loopback only.\n", safeName, key, strings.ToLower(string(handler.HTTPGet.Scheme)), handler.HTTPGet.Port.String(), sanitize(handler.HTTPGet.Path), method)
+		return *handler
+	case handler != nil && handler.TCPSocket != nil:
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s translated to a tcpSocket probe (port %s); the kubelet probes the pod IP, so the app must not listen on loopback only.\n", safeName, key, handler.TCPSocket.Port.String())
+		return *handler
+	case err != nil:
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s could not be translated to a native probe (%s); keeping the exec probe, which requires the tool in the image.\n", safeName, key, sanitize(err.Error()))
+	}
+
+	return corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"sh", "-c", cmd},
+		},
+	}
+}
+
+// durationSeconds parses a Go-style duration (e.g. "30s", "1m") into whole
+// seconds, clamped to int32.
+func durationSeconds(s string) (int32, bool) {
+	if s == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	if d.Seconds() > math.MaxInt32 {
+		return math.MaxInt32, true
+	}
+	return int32(d.Seconds()), true
+}
@@ -20,7 +20,7 @@ HealthCmd=curl -f http://localhost || exit 1
 	unit, _ := parser.Parse(reader)
 	qContainer := quadlet.LoadContainer(unit)
 
-	objs, err := ConvertContainer(qContainer, "app", nil)
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
 	if err != nil {
 		t.Fatalf("ConvertContainer failed: %v", err)
 	}
@@ -53,3 +53,112 @@ HealthCmd=curl -f http://localhost || exit 1
 		t.Errorf("Expected Exec command %v, got %v", expected, probe.Exec.Command)
 	}
 }
+
+func TestConvertContainer_NativeProbes(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=alpine\nHealthCmd=curl -f http://localhost:8080/healthz || exit 1\nHealthStartupCmd=nc -z localhost 5432\n"))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, &Options{NativeProbes: true})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	container := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+	if p := container.LivenessProbe; p == nil || p.HTTPGet == nil || p.HTTPGet.Port.IntVal != 8080 || p.HTTPGet.Path != "/healthz" {
+		t.Errorf("Expected an httpGet liveness probe on 8080/healthz, got %+v", p)
+	}
+	if p := container.StartupProbe; p == nil || p.TCPSocket == nil || p.TCPSocket.Port.IntVal != 5432 {
+		t.Errorf("Expected a tcpSocket startup probe on 5432, got %+v", p)
+	}
+
+	// Without the option, the command keeps probing loopback from inside.
+	objs, err = ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if p := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].LivenessProbe; p == nil || p.Exec == nil {
+		t.Errorf("Expected an exec liveness probe by default, got %+v", p)
+	}
+}
+
+func TestConvertContainer_StartupAndReadinessProbes(t *testing.T) {
+	input := `
+[Container]
+Image=app
+HealthCmd=/bin/check
+HealthInterval=10s
+HealthStartupCmd=/bin/warmup
+HealthStartupInterval=5s
+HealthStartupRetries=30
+HealthStartupTimeout=2s
+Notify=healthy
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	container := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+
+	if container.LivenessProbe == nil {
+		t.Fatal("LivenessProbe is nil")
+	}
+	if container.ReadinessProbe == nil {
+		t.Fatal("ReadinessProbe is nil")
+	}
+	if container.ReadinessProbe.PeriodSeconds != 10 {
+		t.Errorf("Expected readiness PeriodSeconds 10, got %d", container.ReadinessProbe.PeriodSeconds)
+	}
+
+	startup := container.StartupProbe
+	if startup == nil {
+		t.Fatal("StartupProbe is nil")
+	}
+	expected := []string{"sh", "-c", "/bin/warmup"}
+	if !reflect.DeepEqual(startup.Exec.Command, expected) {
+		t.Errorf("Expected startup command %v, got %v", expected, startup.Exec.Command)
+	}
+	if startup.PeriodSeconds != 5 || startup.FailureThreshold != 30 || startup.TimeoutSeconds != 2 {
+		t.Errorf("Unexpected startupProbe timings: %+v", startup)
+	}
+}
+
+func TestConvertContainer_HealthOnFailure(t *testing.T) {
+	tests := []struct {
+		onFailure    string
+		wantLiveness bool
+		wantErr      bool
+	}{
+		{"kill", true, false},
+		{"restart", true, false},
+		{"stop", true, false},
+		{"none", false, false},
+		{"reboot", false, true},
+	}
+
+	for _, tt := range tests {
+		input := "\n[Container]\nImage=app\nHealthCmd=/bin/check\nHealthOnFailure=" + tt.onFailure + "\n"
+		reader := strings.NewReader(input)
+		unit, _ := parser.Parse(reader)
+		qContainer := quadlet.LoadContainer(unit)
+
+		objs, err := ConvertContainer(qContainer, "app", nil, nil)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("HealthOnFailure=%s: expected error, got nil", tt.onFailure)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("HealthOnFailure=%s: ConvertContainer failed: %v", tt.onFailure, err)
+		}
+
+		container := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+		if (container.LivenessProbe != nil) != tt.wantLiveness {
+			t.Errorf("HealthOnFailure=%s: expected liveness probe %v, got %v", tt.onFailure, tt.wantLiveness, container.LivenessProbe != nil)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// failSuffixRe matches the `|| exit 1` idiom that only normalizes the exit code.
+var failSuffixRe = regexp.MustCompile(`\s*\|\|\s*(exit(\s+\d+)?|false)\s*$`)
+
+// nativeHealthHandler recognizes health commands that probe localhost with
+// curl, wget or nc and returns an equivalent httpGet or tcpSocket handler.
+// head reports a HEAD request (curl -I, wget --spider), which the httpGet
+// probe sends as GET. It returns nil for commands using other tools, and an
+// error when a known tool is used in a way that cannot be translated
+// faithfully.
+func nativeHealthHandler(cmd string) (handler *corev1.ProbeHandler, head bool, err error) {
+	s := failSuffixRe.ReplaceAllString(strings.TrimSpace(cmd), "")
+
+	args, err := SplitArgs(s)
+	if err != nil || len(args) == 0 {
+		return nil, false, nil
+	}
+
+	tool := path.Base(args[0])
+	switch tool {
+	case "curl", "wget", "nc", "ncat":
+	default:
+		return nil, false, nil
+	}
+
+	if strings.ContainsAny(s, "|&;<>`$") {
+		return nil, false, fmt.Errorf("%s is combined with other shell commands", tool)
+	}
+
+	switch tool {
+	case "curl":
+		return curlHandler(args[1:])
+	case "wget":
+		return wgetHandler(args[1:])
+	default:
+		handler, err = ncHandler(args[1:])
+		return handler, false, err
+	}
+}
+
+func curlHandler(args []string) (*corev1.ProbeHandler, bool, error) {
+	var rawURL string
+	var headers []corev1.HTTPHeader
+	fail := false
+	head := false
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "-f" || a == "--fail" || a == "--fail-with-body":
+			fail = true
+		case a == "-H" || a == "--header":
+			if i+1 >= len(args) {
+				return nil, false, fmt.Errorf("curl %s without value", a)
+			}
+			i++
+			h, err := parseHTTPHeader(args[i])
+			if err != nil {
+				return nil, false, err
+			}
+			headers = append(headers, h)
+		case strings.HasPrefix(a, "--header="):
+			h, err := parseHTTPHeader(strings.TrimPrefix(a, "--header="))
+			if err != nil {
+				return nil, false, err
+			}
+			headers = append(headers, h)
+		case a == "-o" || a == "--output" || a == "-m" || a == "--max-time" || a == "--connect-timeout" ||
+			a == "--retry" || a == "-w" || a == "--write-out" || a == "-A" || a == "--user-agent":
+			i++
+		case a == "-I" || a == "--head":
+			head = true
+		case a == "-s" || a == "--silent" || a == "-S" || a == "--show-error" || a == "-L" || a == "--location" ||
+			a == "-k" || a == "--insecure" || a == "-v" || a == "--verbose":
+		case strings.HasPrefix(a, "--"):
+			return nil, false, fmt.Errorf("unsupported curl option %s", a)
+		case strings.HasPrefix(a, "-"):
+			// Combined short flags such as -fsSL; an option taking a value
+			// may only come last.
+			flags := a[1:]
+			for j, f := range flags {
+				switch f {
+				case 'f':
+					fail = true
+				case 's', 'S', 'L', 'k', 'v':
+				case 'I':
+					head = true
+				case 'o', 'm', 'w', 'A':
+					if j != len(flags)-1 {
+						return nil, false, fmt.Errorf("unsupported curl option %s", a)
+					}
+					i++
+				default:
+					return nil, false, fmt.Errorf("unsupported curl option -%c", f)
+				}
+			}
+		default:
+			if rawURL != "" {
+				return nil, false, fmt.Errorf("curl requests more than one URL")
+			}
+			rawURL = a
+		}
+	}
+
+	if !fail {
+		return nil, false, fmt.Errorf("curl without --fail succeeds on HTTP errors")
+	}
+	handler, err := httpGetHandler(rawURL, headers)
+	return handler, head, err
+}
+
+func wgetHandler(args []string) (*corev1.ProbeHandler, bool, error) {
+	var rawURL string
+	var headers []corev1.HTTPHeader
+	head := false
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--header":
+			if i+1 >= len(args) {
+				return nil, false, fmt.Errorf("wget %s without value", a)
+			}
+			i++
+			h, err := parseHTTPHeader(args[i])
+			if err != nil {
+				return nil, false, err
+			}
+			headers = append(headers, h)
+		case strings.HasPrefix(a, "--header="):
+			h, err := parseHTTPHeader(strings.TrimPrefix(a, "--header="))
+			if err != nil {
+				return nil, false, err
+			}
+			headers = append(headers, h)
+		case a == "-O" || a == "-T" || a == "-t" || a == "--tries" || a == "--timeout" || a == "-U" || a == "--user-agent":
+			i++
+		case a == "--spider":
+			head = true
+		case a == "-q" || a == "--quiet" || a == "-S" || a == "--server-response" ||
+			a == "-nv" || a == "--no-verbose" || a == "--no-check-certificate",
+			strings.HasPrefix(a, "-O"), strings.HasPrefix(a, "--output-document="),
+			strings.HasPrefix(a, "--timeout="), strings.HasPrefix(a, "--tries="), strings.HasPrefix(a, "-T"):
+		case strings.HasPrefix(a, "--"):
+			return nil, false, fmt.Errorf("unsupported wget option %s", a)
+		case strings.HasPrefix(a, "-"):
+			// Combined short flags such as -qO-; -O and -T take the rest of
+			// the argument, or the next one, as their value.
+		flags:
+			for j, f := range a[1:] {
+				switch f {
+				case 'q', 'S':
+				case 'O', 'T', 't':
+					if j == len(a)-2 {
+						i++
+					}
+					break flags
+				default:
+					return nil, false, fmt.Errorf("unsupported wget option -%c", f)
+				}
+			}
+		default:
+			if rawURL != "" {
+				return nil, false, fmt.Errorf("wget requests more than one URL")
+			}
+			rawURL = a
+		}
+	}
+
+	handler, err := httpGetHandler(rawURL, headers)
+	return handler, head, err
+}
+
+func ncHandler(args []string) (*corev1.ProbeHandler, error) {
+	var positional []string
+	scan := false
+
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "-w":
+			i++
+		case strings.HasPrefix(a, "-"):
+			for _, f := range a[1:] {
+				switch f {
+				case 'z':
+					scan = true
+				case 'v':
+				default:
+					return nil, fmt.Errorf("unsupported nc option -%c", f)
+				}
+			}
+		default:
+			positional = append(positional, a)
+		}
+	}
+
+	if !scan {
+		return nil, fmt.Errorf("nc without -z does more than check the port")
+	}
+	if len(positional) != 2 {
+		return nil, fmt.Errorf("nc expects a host and a single port")
+	}
+	if !isLocalHost(positional[0]) {
+		return nil, fmt.Errorf("nc checks remote host %s", positional[0])
+	}
+	port, err := parsePortNumber(positional[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &corev1.ProbeHandler{
+		TCPSocket: &corev1.TCPSocketAction{
+			Port: intstr.FromInt32(port),
+		},
+	}, nil
+}
+
+// httpGetHandler builds an httpGet handler for a localhost URL.
+func httpGetHandler(rawURL string, headers []corev1.HTTPHeader) (*corev1.ProbeHandler, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("no URL")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %s", rawURL)
+	}
+
+	scheme := corev1.URISchemeHTTP
+	port := int32(80)
+	switch u.Scheme {
+	case "http":
+	case "https":
+		scheme = corev1.URISchemeHTTPS
+		port = 443
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %s", u.Scheme)
+	}
+
+	if !isLocalHost(u.Hostname()) {
+		return nil, fmt.Errorf("URL targets remote host %s", u.Hostname())
+	}
+	if p := u.Port(); p != "" {
+		if port, err = parsePortNumber(p); err != nil {
+			return nil, err
+		}
+	}
+
+	reqPath := u.EscapedPath()
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	if u.RawQuery != "" {
+		reqPath += "?" + u.RawQuery
+	}
+
+	return &corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:        reqPath,
+			Port:        intstr.FromInt32(port),
+			Scheme:      scheme,
+			HTTPHeaders: headers,
+		},
+	}, nil
+}
+
+func parseHTTPHeader(s string) (corev1.HTTPHeader, error) {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return corev1.HTTPHeader{}, fmt.Errorf("invalid header %q", s)
+	}
+	return corev1.HTTPHeader{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)}, nil
+}
+
+// isLocalHost reports whether host addresses the container itself.
+func isLocalHost(host string) bool {
+	switch strings.Trim(host, "[]") {
+	case "localhost", "127.0.0.1", "::1", "0.0.0.0":
+		return true
+	}
+	return false
+}
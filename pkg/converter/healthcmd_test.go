@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNativeHealthHandler_HTTP(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		scheme corev1.URIScheme
+		port   int32
+		path   string
+	}{
+		{"curl -f http://localhost:8080/healthz || exit 1", corev1.URISchemeHTTP, 8080, "/healthz"},
+		{"curl -fsSL -o /dev/null https://127.0.0.1/ready?full=1", corev1.URISchemeHTTPS, 443, "/ready?full=1"},
+		{"/usr/bin/curl --fail --silent localhost:3000", corev1.URISchemeHTTP, 3000, "/"},
+		{"wget -q -O /dev/null http://localhost:9000/ping", corev1.URISchemeHTTP, 9000, "/ping"},
+		{"wget -qO- http://[::1]:8081/status || false", corev1.URISchemeHTTP, 8081, "/status"},
+	}
+
+	for _, tt := range tests {
+		h, _, err := nativeHealthHandler(tt.cmd)
+		if err != nil || h == nil || h.HTTPGet == nil {
+			t.Errorf("%q: expected httpGet handler, got %v (err %v)", tt.cmd, h, err)
+			continue
+		}
+		if h.HTTPGet.Scheme != tt.scheme || h.HTTPGet.Port.IntVal != tt.port || h.HTTPGet.Path != tt.path {
+			t.Errorf("%q: got %s port %d path %s", tt.cmd, h.HTTPGet.Scheme, h.HTTPGet.Port.IntVal, h.HTTPGet.Path)
+		}
+	}
+}
+
+func TestNativeHealthHandler_Headers(t *testing.T) {
+	h, _, err := nativeHealthHandler(`curl -f -H "Host: app.internal" http://localhost/healthz`)
+	if err != nil || h == nil || h.HTTPGet == nil {
+		t.Fatalf("Expected httpGet handler, got %v (err %v)", h, err)
+	}
+	if len(h.HTTPGet.HTTPHeaders) != 1 || h.HTTPGet.HTTPHeaders[0].Name != "Host" || h.HTTPGet.HTTPHeaders[0].Value != "app.internal" {
+		t.Errorf("Unexpected headers: %v", h.HTTPGet.HTTPHeaders)
+	}
+}
+
+func TestNativeHealthHandler_Head(t *testing.T) {
+	for _, cmd := range []string{
+		"curl -fI http://localhost/",
+		"curl -f --head http://localhost/",
+		"wget -q --spider http://localhost:8080/health",
+	} {
+		h, head, err := nativeHealthHandler(cmd)
+		if err != nil || h == nil || h.HTTPGet == nil {
+			t.Errorf("%q: expected httpGet handler, got %v (err %v)", cmd, h, err)
+			continue
+		}
+		if !head {
+			t.Errorf("%q: expected the HEAD request to be reported", cmd)
+		}
+	}
+
+	if _, head, _ := nativeHealthHandler("curl -f http://localhost/"); head {
+		t.Error("Expected a GET request not to be reported as HEAD")
+	}
+}
+
+func TestNativeHealthHandler_TCP(t *testing.T) {
+	h, _, err := nativeHealthHandler("nc -zv -w 2 localhost 5432")
+	if err != nil || h == nil || h.TCPSocket == nil {
+		t.Fatalf("Expected tcpSocket handler, got %v (err %v)", h, err)
+	}
+	if h.TCPSocket.Port.IntVal != 5432 {
+		t.Errorf("Expected port 5432, got %d", h.TCPSocket.Port.IntVal)
+	}
+}
+
+func TestNativeHealthHandler_Fallback(t *testing.T) {
+	// Other tools are left alone without an error.
+	if h, _, err := nativeHealthHandler("pg_isready -U postgres"); h != nil || err != nil {
+		t.Errorf("Expected no translation for pg_isready, got %v (err %v)", h, err)
+	}
+
+	// Known tools used in ways that cannot be mapped report why.
+	for _, cmd := range []string{
+		"curl http://localhost/healthz",
+		"curl -f http://db:5432/",
+		"curl -f http://localhost/ && touch /tmp/ok",
+		"curl -f --data x http://localhost/",
+		"nc localhost 80",
+	} {
+		if h, _, err := nativeHealthHandler(cmd); h != nil || err == nil {
+			t.Errorf("%q: expected fallback with reason, got %v (err %v)", cmd, h, err)
+		}
+	}
+}
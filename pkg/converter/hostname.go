@@ -0,0 +1,65 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateDNSLabel checks that a value used as a Kubernetes name or hostname
+// is a DNS-1123 label.
+func validateDNSLabel(key string, value string) error {
+	if errs := validation.IsDNS1123Label(value); len(errs) > 0 {
+		return fmt.Errorf("invalid %s %q: %s", key, value, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// applyHostName sets spec.hostname and makes it resolvable as
+// <hostname>.<subdomain> through a governing headless Service. The unit's own
+// Service is reused when it is headless; otherwise a <name>-headless Service
+// is returned.
+func applyHostName(name string, hostname string, spec *corev1.PodSpec, labels map[string]string, service *corev1.Service) (*corev1.Service, error) {
+	if hostname == "" {
+		return nil, nil
+	}
+	if err := validateDNSLabel("HostName", hostname); err != nil {
+		return nil, err
+	}
+	if spec.HostNetwork {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: HostName is ignored with Network=host; the pod uses the node's hostname.\n", sanitize(name))
+		return nil, nil
+	}
+
+	spec.Hostname = hostname
+	if service != nil && service.Spec.ClusterIP == corev1.ClusterIPNone {
+		spec.Subdomain = service.Name
+		return nil, nil
+	}
+
+	subdomain := name + "-headless"
+	if err := validateDNSLabel("Service name", subdomain); err != nil {
+		return nil, err
+	}
+	spec.Subdomain = subdomain
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   subdomain,
+			Labels: labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector:  labels,
+			ClusterIP: corev1.ClusterIPNone,
+			Ports:     peerPorts(spec),
+		},
+	}, nil
+}
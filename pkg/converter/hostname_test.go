@@ -0,0 +1,105 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertContainer_HostName(t *testing.T) {
+	input := `
+[Container]
+Image=app
+HostName=app01
+PublishPort=8080:80
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	if spec.Hostname != "app01" || spec.Subdomain != "app-headless" {
+		t.Errorf("Expected hostname app01 and subdomain app-headless, got %q and %q", spec.Hostname, spec.Subdomain)
+	}
+
+	var governing *corev1.Service
+	for _, obj := range objs {
+		if s, ok := obj.(*corev1.Service); ok && s.Name == "app-headless" {
+			governing = s
+		}
+	}
+	if governing == nil {
+		t.Fatal("Governing Service app-headless not found")
+	}
+	if governing.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("Expected headless governing Service, got clusterIP %q", governing.Spec.ClusterIP)
+	}
+}
+
+func TestConvertContainer_HostNameHeadlessService(t *testing.T) {
+	input := `
+[Container]
+Image=app
+HostName=app01
+PublishPort=80
+Label=kuadlet.io/service-type=Headless
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec; spec.Subdomain != "app" {
+		t.Errorf("Expected the headless unit Service as subdomain, got %q", spec.Subdomain)
+	}
+	if len(objs) != 2 {
+		t.Errorf("Expected no additional governing Service, got %d objects", len(objs))
+	}
+}
+
+func TestConvertContainer_InvalidHostName(t *testing.T) {
+	input := `
+[Container]
+Image=app
+HostName=app.example.com
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	_, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid HostName") {
+		t.Errorf("Expected invalid HostName error, got %v", err)
+	}
+}
+
+func TestConvertPod_PodName(t *testing.T) {
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\nPodName=shop\nPublishPort=8080:80\n"))
+	cUnit, _ := parser.Parse(strings.NewReader("[Container]\nImage=web\n"))
+
+	objs, err := ConvertPod(quadlet.LoadPod(pUnit), []*quadlet.ContainerUnit{quadlet.LoadContainer(cUnit)}, []string{"web"}, "shop-pod", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	if d := objs[0].(*appsv1.Deployment); d.Name != "shop" {
+		t.Errorf("Expected Deployment shop, got %s", d.Name)
+	}
+	if s := findService(objs); s == nil || s.Name != "shop" || s.Spec.Selector["app.kubernetes.io/name"] != "shop" {
+		t.Errorf("Expected Service shop selecting the pod, got %+v", s)
+	}
+
+	pUnit, _ = parser.Parse(strings.NewReader("[Pod]\nPodName=Shop_1\n"))
+	_, err = ConvertPod(quadlet.LoadPod(pUnit), nil, nil, "shop-pod", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid PodName") {
+		t.Errorf("Expected invalid PodName error, got %v", err)
+	}
+}
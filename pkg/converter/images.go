@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ParseAutoUpdateAnnotation parses an --auto-update-annotation entry,
+// <key>=<value>.
+func ParseAutoUpdateAnnotation(s string) (key string, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid annotation %q: expected <key>=<value>", s)
+	}
+	return key, value, nil
+}
+
+// imageTag returns the tag of an image reference, "latest" when it has
+// neither tag nor digest, and "" for digest references.
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); ok {
+		return tag
+	}
+	return "latest"
+}
+
+// pullPolicy maps Pull= and AutoUpdate= onto the container's
+// imagePullPolicy. AutoUpdate=registry needs the registry checked on every
+// start, so it forces Always.
+func pullPolicy(c *quadlet.ContainerUnit, name string, container *corev1.Container) error {
+	switch strings.ToLower(c.Container.Pull) {
+	case "":
+	case "always":
+		container.ImagePullPolicy = corev1.PullAlways
+	case "missing":
+		container.ImagePullPolicy = corev1.PullIfNotPresent
+	case "never":
+		container.ImagePullPolicy = corev1.PullNever
+	case "newer":
+		// Always only downloads layers whose digest changed, which is
+		// Podman's "newer" without the timestamp comparison.
+		container.ImagePullPolicy = corev1.PullAlways
+	default:
+		return fmt.Errorf("invalid Pull %q (expected always, missing, never or newer)", c.Container.Pull)
+	}
+
+	switch strings.ToLower(c.Container.AutoUpdate) {
+	case "":
+	case "registry":
+		if container.ImagePullPolicy != "" && container.ImagePullPolicy != corev1.PullAlways {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: AutoUpdate=registry overrides Pull=%s; using imagePullPolicy Always.\n", sanitize(name), sanitize(c.Container.Pull))
+		}
+		container.ImagePullPolicy = corev1.PullAlways
+	case "local":
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: AutoUpdate=local follows images in the host's local storage and has no Kubernetes equivalent; ignoring.\n", sanitize(name))
+	default:
+		return fmt.Errorf("invalid AutoUpdate %q (expected registry or local)", c.Container.AutoUpdate)
+	}
+
+	if container.ImagePullPolicy == "" && imageTag(container.Image) == "latest" {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: image %s uses :latest without Pull=; Kubernetes will pull it on every start, so pin a tag or set Pull= explicitly.\n", sanitize(name), sanitize(container.Image))
+	}
+	return nil
+}
+
+// autoUpdateAnnotations returns opts.AutoUpdateAnnotations if any of the
+// containers sets AutoUpdate=registry.
+func autoUpdateAnnotations(containers []*quadlet.ContainerUnit, opts Options) map[string]string {
+	for _, c := range containers {
+		if strings.EqualFold(c.Container.AutoUpdate, "registry") {
+			return opts.AutoUpdateAnnotations
+		}
+	}
+	return nil
+}
+
+// resolveImage resolves Image= references to .image and .build units to the
+// image names they produce: an .image unit's ImageTag, or its Image, and a
+// .build unit's first ImageTag.
+func resolveImage(image string, name string, opts Options) (string, error) {
+	switch {
+	case strings.HasSuffix(image, ".image"):
+		unit := strings.TrimSuffix(image, ".image")
+		i, ok := opts.Images[unit]
+		if !ok {
+			return "", fmt.Errorf("image unit %s not found", image)
+		}
+		if i.Image.ImageTag != "" {
+			return i.Image.ImageTag, nil
+		}
+		if i.Image.Image == "" {
+			return "", fmt.Errorf("image unit %s sets no Image", image)
+		}
+		return i.Image.Image, nil
+	case strings.HasSuffix(image, ".build"):
+		unit := strings.TrimSuffix(image, ".build")
+		b, ok := opts.Builds[unit]
+		if !ok {
+			return "", fmt.Errorf("build unit %s not found", image)
+		}
+		if len(b.Build.ImageTag) == 0 {
+			return "", fmt.Errorf("build unit %s sets no ImageTag", image)
+		}
+		tag := b.Build.ImageTag[0]
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: image %s is built locally by %s; push it to a registry the cluster can pull from before applying.\n", sanitize(name), sanitize(tag), sanitize(image))
+		return tag, nil
+	}
+	return image, nil
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertContainer_PullPolicy(t *testing.T) {
+	cases := map[string]corev1.PullPolicy{
+		"Pull=always":                       corev1.PullAlways,
+		"Pull=missing":                      corev1.PullIfNotPresent,
+		"Pull=never":                        corev1.PullNever,
+		"Pull=newer":                        corev1.PullAlways,
+		"Pull=missing\nAutoUpdate=registry": corev1.PullAlways,
+		"":                                  "",
+	}
+	for settings, want := range cases {
+		unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=docker.io/library/nginx:1.27\n" + settings + "\n"))
+		objs, err := ConvertContainer(quadlet.LoadContainer(unit), "web", nil, nil)
+		if err != nil {
+			t.Fatalf("%q: ConvertContainer failed: %v", settings, err)
+		}
+		if got := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].ImagePullPolicy; got != want {
+			t.Errorf("%q: expected %q, got %q", settings, want, got)
+		}
+	}
+}
+
+func TestConvertContainer_AutoUpdateAnnotation(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=registry.example.com/app:stable\nAutoUpdate=registry\n"))
+	opts := &Options{AutoUpdateAnnotations: map[string]string{"keel.sh/policy": "force"}}
+
+	objs, err := ConvertContainer(quadlet.LoadContainer(unit), "app", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if got := objs[0].(*appsv1.Deployment).Annotations["keel.sh/policy"]; got != "force" {
+		t.Errorf("Expected keel.sh/policy annotation, got %q", got)
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	cases := map[string]string{
+		"nginx":                         "latest",
+		"localhost:5000/app":            "latest",
+		"localhost:5000/app:1.0":        "1.0",
+		"quay.io/org/app:latest":        "latest",
+		"quay.io/org/app@sha256:abc123": "",
+	}
+	for image, want := range cases {
+		if got := imageTag(image); got != want {
+			t.Errorf("imageTag(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestConvertContainer_ImageReferences(t *testing.T) {
+	iUnit, _ := parser.Parse(strings.NewReader("[Image]\nImage=quay.io/org/app:1.2\n"))
+	bUnit, _ := parser.Parse(strings.NewReader("[Build]\nImageTag=registry.example.com/tool:dev\nImageTag=localhost/tool\n"))
+	opts := &Options{
+		Images: map[string]*quadlet.ImageUnit{"app": quadlet.LoadImage(iUnit)},
+		Builds: map[string]*quadlet.BuildUnit{"tool": quadlet.LoadBuild(bUnit)},
+	}
+
+	for ref, want := range map[string]string{
+		"app.image":  "quay.io/org/app:1.2",
+		"tool.build": "registry.example.com/tool:dev",
+		"nginx:1.27": "nginx:1.27",
+	} {
+		unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=" + ref + "\n"))
+		objs, err := ConvertContainer(quadlet.LoadContainer(unit), "c", nil, opts)
+		if err != nil {
+			t.Fatalf("%s: ConvertContainer failed: %v", ref, err)
+		}
+		if got := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image; got != want {
+			t.Errorf("%s: expected image %s, got %s", ref, want, got)
+		}
+	}
+
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=missing.image\n"))
+	if _, err := ConvertContainer(quadlet.LoadContainer(unit), "c", nil, opts); err == nil || !strings.Contains(err.Error(), "missing.image not found") {
+		t.Errorf("Expected an error for a missing image unit, got %v", err)
+	}
+}
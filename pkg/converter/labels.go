@@ -0,0 +1,190 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// consumedKey reports whether a Label= or Annotation= key configures the
+// conversion itself (kuadlet.io/ settings, Traefik and Caddy routing) rather
+// than being metadata to propagate.
+func consumedKey(k string) bool {
+	return strings.HasPrefix(k, "kuadlet.io/") ||
+		strings.HasPrefix(k, "traefik.") ||
+		k == "caddy" || strings.HasPrefix(k, "caddy.") || strings.HasPrefix(k, "caddy_")
+}
+
+// unitMetadata accumulates the labels and annotations of the units making up
+// a Deployment. The first unit to set a key wins.
+type unitMetadata struct {
+	unit        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func newUnitMetadata(unit string, selector map[string]string) *unitMetadata {
+	m := &unitMetadata{
+		unit:        unit,
+		Labels:      make(map[string]string, len(selector)),
+		Annotations: make(map[string]string),
+	}
+	for k, v := range selector {
+		m.Labels[k] = v
+	}
+	return m
+}
+
+// addLabels merges Label= entries, rewriting keys and values that are not
+// valid Kubernetes labels.
+func (m *unitMetadata) addLabels(source string, labels map[string]string) {
+	for _, k := range sortedKeys(labels) {
+		if consumedKey(k) {
+			continue
+		}
+		v := labels[k]
+		key, value := labelKey(k), labelValue(v)
+		if key == "" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: label %s cannot be expressed as a Kubernetes label key; dropping it.\n", sanitize(source), sanitize(k))
+			continue
+		}
+		if key != k || value != v {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: label %s=%s rewritten to %s=%s to satisfy Kubernetes label syntax.\n", sanitize(source), sanitize(k), sanitize(v), sanitize(key), sanitize(value))
+		}
+		m.set(m.Labels, "label", source, key, value)
+	}
+}
+
+// addAnnotations merges Annotation= entries. Values are unrestricted, keys
+// follow the label key syntax.
+func (m *unitMetadata) addAnnotations(source string, annotations map[string]string) {
+	for _, k := range sortedKeys(annotations) {
+		if consumedKey(k) {
+			continue
+		}
+		key := labelKey(k)
+		if key == "" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: annotation %s cannot be expressed as a Kubernetes annotation key; dropping it.\n", sanitize(source), sanitize(k))
+			continue
+		}
+		if key != k {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: annotation key %s rewritten to %s to satisfy Kubernetes key syntax.\n", sanitize(source), sanitize(k), sanitize(key))
+		}
+		m.set(m.Annotations, "annotation", source, key, annotations[k])
+	}
+}
+
+func (m *unitMetadata) set(target map[string]string, kind string, source string, key string, value string) {
+	if prev, ok := target[key]; ok {
+		if prev != value {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s %s=%s is ignored; %s already sets it to %s.\n", sanitize(source), kind, sanitize(key), sanitize(value), sanitize(m.unit), sanitize(prev))
+		}
+		return
+	}
+	target[key] = value
+}
+
+// apply sets the merged metadata on the Deployment, its pod template and the
+// Services among objects. Selectors are left untouched.
+func (m *unitMetadata) apply(objects []runtime.Object) {
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			o.Labels = mergeMaps(o.Labels, m.Labels)
+			o.Spec.Template.Labels = mergeMaps(o.Spec.Template.Labels, m.Labels)
+			o.Spec.Template.Annotations = mergeMaps(o.Spec.Template.Annotations, m.Annotations)
+		case *corev1.Service:
+			o.Labels = mergeMaps(o.Labels, m.Labels)
+		}
+	}
+}
+
+// mergeMaps returns a new map with the entries of extra added to base;
+// entries of base win.
+func mergeMaps(base map[string]string, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return base
+	}
+	result := make(map[string]string, len(base)+len(extra))
+	for k, v := range extra {
+		result[k] = v
+	}
+	for k, v := range base {
+		result[k] = v
+	}
+	return result
+}
+
+// labelKey rewrites a key into a valid qualified name, [prefix/]name, or
+// returns "" if nothing valid remains.
+func labelKey(k string) string {
+	if len(validation.IsQualifiedName(k)) == 0 {
+		return k
+	}
+	prefix, name, hasPrefix := strings.Cut(k, "/")
+	if !hasPrefix {
+		prefix, name = "", k
+	}
+	name = labelValue(name)
+	if name == "" {
+		return ""
+	}
+	if hasPrefix {
+		prefix = strings.Trim(rewriteChars(strings.ToLower(prefix), "-."), "-.")
+		if len(prefix) > validation.DNS1123SubdomainMaxLength {
+			prefix = strings.Trim(prefix[:validation.DNS1123SubdomainMaxLength], "-.")
+		}
+		if len(validation.IsDNS1123Subdomain(prefix)) > 0 {
+			return ""
+		}
+		name = prefix + "/" + name
+	}
+	if len(validation.IsQualifiedName(name)) > 0 {
+		return ""
+	}
+	return name
+}
+
+// labelValue rewrites a value into a valid label value: at most 63
+// characters of [A-Za-z0-9-_.], starting and ending alphanumerically.
+func labelValue(v string) string {
+	if len(validation.IsValidLabelValue(v)) == 0 {
+		return v
+	}
+	v = rewriteChars(v, "-_.")
+	if len(v) > validation.LabelValueMaxLength {
+		v = v[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(v, "-_.")
+}
+
+// rewriteChars replaces characters other than ASCII alphanumerics and allowed
+// with '-'.
+func rewriteChars(s string, allowed string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(allowed, r)) {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestConvertContainer_LabelPropagation(t *testing.T) {
+	input := `
+[Container]
+Image=app
+PublishPort=8080:80
+Label=team=payments
+Label=prometheus.io/scrape=true
+Label=version=1.2 (beta)
+Label=kuadlet.io/service-type=NodePort
+Label=traefik.enable=true
+Label=app.kubernetes.io/name=other
+Annotation=example.com/owner=Payments Team <payments@example.com>
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	deployment := objs[0].(*appsv1.Deployment)
+	service := findService(objs)
+	if service == nil {
+		t.Fatal("Service not found")
+	}
+
+	for name, labels := range map[string]map[string]string{
+		"Deployment":   deployment.Labels,
+		"pod template": deployment.Spec.Template.Labels,
+		"Service":      service.Labels,
+	} {
+		if labels["team"] != "payments" || labels["prometheus.io/scrape"] != "true" {
+			t.Errorf("%s: expected unit labels, got %v", name, labels)
+		}
+		if labels["version"] != "1.2--beta" {
+			t.Errorf("%s: expected rewritten version label, got %q", name, labels["version"])
+		}
+		if labels["app.kubernetes.io/name"] != "app" {
+			t.Errorf("%s: selector label must not be overridden, got %q", name, labels["app.kubernetes.io/name"])
+		}
+		if _, ok := labels["kuadlet.io/service-type"]; ok {
+			t.Errorf("%s: kuadlet.io/ settings must not be propagated", name)
+		}
+		if _, ok := labels["traefik.enable"]; ok {
+			t.Errorf("%s: routing labels must not be propagated", name)
+		}
+	}
+
+	if len(deployment.Spec.Selector.MatchLabels) != 1 || len(service.Spec.Selector) != 1 {
+		t.Errorf("Expected minimal selectors, got %v and %v", deployment.Spec.Selector.MatchLabels, service.Spec.Selector)
+	}
+	if got := deployment.Spec.Template.Annotations["example.com/owner"]; got != "Payments Team <payments@example.com>" {
+		t.Errorf("Expected annotation on pod template, got %q", got)
+	}
+}
+
+func TestLabelKeyAndValue(t *testing.T) {
+	cases := []struct{ in, key, value string }{
+		{"valid", "valid", "valid"},
+		{"Example.COM/tier", "example.com/tier", "Example.COM-tier"},
+		{"my key", "my-key", "my-key"},
+		{"-edge-", "edge", "edge"},
+		{"!!!", "", ""},
+	}
+	for _, c := range cases {
+		if got := labelKey(c.in); got != c.key {
+			t.Errorf("labelKey(%q) = %q, want %q", c.in, got, c.key)
+		}
+		if got := labelValue(c.in); got != c.value {
+			t.Errorf("labelValue(%q) = %q, want %q", c.in, got, c.value)
+		}
+	}
+	if got := labelValue(strings.Repeat("a", 70)); len(got) != 63 {
+		t.Errorf("Expected value truncated to 63 characters, got %d", len(got))
+	}
+}
@@ -0,0 +1,393 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// multusNetworksAnnotation attaches a pod to NetworkAttachmentDefinitions.
+const multusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+
+// cniVersion is the CNI spec version of generated configurations.
+const cniVersion = "0.4.0"
+
+// IPAM plugins usable for Multus attachments.
+const (
+	IPAMHostLocal   = "host-local"
+	IPAMWhereabouts = "whereabouts"
+)
+
+// ParseIPAM validates an IPAM plugin name.
+func ParseIPAM(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case IPAMHostLocal:
+		return IPAMHostLocal, nil
+	case IPAMWhereabouts:
+		return IPAMWhereabouts, nil
+	}
+	return "", fmt.Errorf("unknown IPAM plugin %q (expected host-local or whereabouts)", s)
+}
+
+// networkAttachment builds a k8s.cni.cncf.io/v1 NetworkAttachmentDefinition
+// reproducing the Podman network as a secondary pod interface.
+func networkAttachment(n *quadlet.NetworkUnit, name string, opts Options) (*unstructured.Unstructured, error) {
+	config, err := cniConfig(n, name, opts)
+	if err != nil {
+		return nil, fmt.Errorf("network %s: %w", name, err)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("network %s: %w", name, err)
+	}
+
+	nad := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "k8s.cni.cncf.io/v1",
+		"kind":       "NetworkAttachmentDefinition",
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"spec": map[string]interface{}{
+			"config": string(data),
+		},
+	}}
+	if len(n.Network.Label) > 0 {
+		nad.SetLabels(n.Network.Label)
+	}
+	return nad, nil
+}
+
+// cniConfig maps Driver, InterfaceName and Options onto a bridge, macvlan or
+// ipvlan plugin configuration.
+func cniConfig(n *quadlet.NetworkUnit, name string, opts Options) (map[string]interface{}, error) {
+	ns := &n.Network
+	netOpts := networkOptions(ns.Options)
+
+	cniName := ns.NetworkName
+	if cniName == "" {
+		cniName = name
+	}
+	config := map[string]interface{}{
+		"cniVersion": cniVersion,
+		"name":       cniName,
+	}
+
+	driver := strings.ToLower(ns.Driver)
+	switch driver {
+	case "", "bridge":
+		config["type"] = "bridge"
+		bridge := ns.InterfaceName
+		if bridge == "" {
+			// Linux limits interface names to 15 characters.
+			bridge = name
+			if len(bridge) > 15 {
+				bridge = bridge[:15]
+			}
+		}
+		config["bridge"] = bridge
+		config["isGateway"] = !ns.Internal
+		config["ipMasq"] = !ns.Internal
+		if v, ok := netOpts["vlan"]; ok {
+			vlan, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vlan option %q", v)
+			}
+			config["vlan"] = vlan
+		}
+	case "macvlan", "ipvlan":
+		config["type"] = driver
+		master := netOpts["parent"]
+		if master == "" {
+			master = ns.InterfaceName
+		}
+		if master != "" {
+			config["master"] = master
+		}
+		if mode, ok := netOpts["mode"]; ok {
+			config["mode"] = mode
+		}
+	default:
+		return nil, fmt.Errorf("Driver=%s has no CNI equivalent (expected bridge, macvlan or ipvlan)", ns.Driver)
+	}
+
+	if v, ok := netOpts["mtu"]; ok {
+		mtu, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mtu option %q", v)
+		}
+		config["mtu"] = mtu
+	}
+
+	ipam, err := cniIPAM(n, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	config["ipam"] = ipam
+	if ipam["type"] == IPAMHostLocal {
+		// Lets pods request static addresses through the networks annotation.
+		config["capabilities"] = map[string]interface{}{"ips": true}
+	}
+	return config, nil
+}
+
+// ipamRange is a subnet with the Gateway= and IPRange= entries at its index,
+// as Podman pairs them.
+type ipamRange struct {
+	Subnet     netip.Prefix
+	Gateway    string
+	RangeStart string
+	RangeEnd   string
+}
+
+// cniIPAM builds the IPAM section from Subnet, Gateway and IPRange.
+func cniIPAM(n *quadlet.NetworkUnit, name string, opts Options) (map[string]interface{}, error) {
+	ns := &n.Network
+
+	plugin := opts.MultusIPAM
+	switch strings.ToLower(ns.IPAMDriver) {
+	case "":
+	case "host-local":
+		plugin = IPAMHostLocal
+	case "whereabouts":
+		plugin = IPAMWhereabouts
+	case "dhcp":
+		return map[string]interface{}{"type": "dhcp"}, nil
+	case "none":
+		return map[string]interface{}{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported IPAMDriver=%s", ns.IPAMDriver)
+	}
+
+	if len(ns.Subnet) == 0 {
+		return nil, fmt.Errorf("Subnet= is required to generate %s IPAM; Podman allocates one automatically, Kubernetes cannot", plugin)
+	}
+	ranges, err := ipamRanges(ns)
+	if err != nil {
+		return nil, err
+	}
+	if ns.IPv6 {
+		hasIPv6 := false
+		for _, r := range ranges {
+			hasIPv6 = hasIPv6 || r.Subnet.Addr().Is6()
+		}
+		if !hasIPv6 {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: network %s: IPv6=true without an IPv6 Subnet=; the attachment is IPv4 only.\n", sanitize(name))
+		}
+	}
+
+	if plugin == IPAMWhereabouts {
+		var list []interface{}
+		for _, r := range ranges {
+			entry := map[string]interface{}{"range": r.Subnet.String()}
+			if r.RangeStart != "" {
+				entry["range_start"] = r.RangeStart
+				entry["range_end"] = r.RangeEnd
+			}
+			if r.Gateway != "" {
+				entry["gateway"] = r.Gateway
+			}
+			list = append(list, entry)
+		}
+		ipam := map[string]interface{}{"type": IPAMWhereabouts}
+		if len(list) == 1 {
+			for k, v := range list[0].(map[string]interface{}) {
+				ipam[k] = v
+			}
+		} else {
+			ipam["ipRanges"] = list
+		}
+		return ipam, nil
+	}
+
+	var sets []interface{}
+	for _, r := range ranges {
+		entry := map[string]interface{}{"subnet": r.Subnet.String()}
+		if r.RangeStart != "" {
+			entry["rangeStart"] = r.RangeStart
+			entry["rangeEnd"] = r.RangeEnd
+		}
+		if r.Gateway != "" {
+			entry["gateway"] = r.Gateway
+		}
+		sets = append(sets, []interface{}{entry})
+	}
+	return map[string]interface{}{
+		"type":   IPAMHostLocal,
+		"ranges": sets,
+	}, nil
+}
+
+func ipamRanges(ns *quadlet.NetworkSection) ([]ipamRange, error) {
+	if len(ns.Gateway) > len(ns.Subnet) || len(ns.IPRange) > len(ns.Subnet) {
+		return nil, fmt.Errorf("each Gateway= and IPRange= must belong to a Subnet=")
+	}
+
+	var ranges []ipamRange
+	for i, s := range ns.Subnet {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Subnet %q", s)
+		}
+		r := ipamRange{Subnet: prefix.Masked()}
+
+		if i < len(ns.Gateway) {
+			gw, err := netip.ParseAddr(ns.Gateway[i])
+			if err != nil || !r.Subnet.Contains(gw) {
+				return nil, fmt.Errorf("Gateway %q is not an address in subnet %s", ns.Gateway[i], r.Subnet)
+			}
+			r.Gateway = gw.String()
+		}
+
+		if i < len(ns.IPRange) {
+			start, end, err := parseIPRange(ns.IPRange[i])
+			if err != nil {
+				return nil, err
+			}
+			if !r.Subnet.Contains(start) || !r.Subnet.Contains(end) {
+				return nil, fmt.Errorf("IPRange %q is outside subnet %s", ns.IPRange[i], r.Subnet)
+			}
+			r.RangeStart, r.RangeEnd = start.String(), end.String()
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// parseIPRange accepts the IPRange= forms "10.0.0.128/25" and
+// "10.0.0.10-10.0.0.20".
+func parseIPRange(s string) (netip.Addr, netip.Addr, error) {
+	if from, to, ok := strings.Cut(s, "-"); ok {
+		start, errStart := netip.ParseAddr(from)
+		end, errEnd := netip.ParseAddr(to)
+		if errStart != nil || errEnd != nil || end.Less(start) {
+			return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IPRange %q", s)
+		}
+		return start, end, nil
+	}
+
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Addr{}, netip.Addr{}, fmt.Errorf("invalid IPRange %q", s)
+	}
+	prefix = prefix.Masked()
+	last := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(last)*8; bit++ {
+		last[bit/8] |= 0x80 >> (bit % 8)
+	}
+	end, _ := netip.AddrFromSlice(last)
+	return prefix.Addr(), end, nil
+}
+
+// networkOptions parses Options= entries; each may hold several
+// comma-separated key=value pairs.
+func networkOptions(values []string) map[string]string {
+	result := make(map[string]string)
+	for _, v := range values {
+		for _, opt := range strings.Split(v, ",") {
+			k, val, _ := strings.Cut(opt, "=")
+			result[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+	}
+	return result
+}
+
+// multusSelection is an entry of the networks annotation.
+type multusSelection struct {
+	Name      string   `json:"name"`
+	Interface string   `json:"interface,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+}
+
+// applyMultusNetworks attaches a pod template to the networks a unit joins.
+// Static addresses come from the ip=/ip6= options of Network= entries, or
+// from IP=/IP6= when the unit joins a single network.
+func applyMultusNetworks(unit string, template *corev1.PodTemplateSpec, joined []joinedNetwork, ip, ip6 string, opts Options) error {
+	if !opts.Multus || len(joined) == 0 {
+		return nil
+	}
+	if (ip != "" || ip6 != "") && len(joined) > 1 {
+		return fmt.Errorf("%s: IP= and IP6= require exactly one network; use Network=name.network:ip=... instead", unit)
+	}
+
+	var selections []multusSelection
+	for _, j := range joined {
+		n := opts.Networks[j.Name]
+		sel := multusSelection{Name: j.Name, Interface: j.Options["interface_name"]}
+
+		addrs := []string{j.Options["ip"], j.Options["ip6"]}
+		if len(joined) == 1 {
+			if addrs[0] == "" {
+				addrs[0] = ip
+			}
+			if addrs[1] == "" {
+				addrs[1] = ip6
+			}
+		}
+		for _, a := range addrs {
+			if a == "" {
+				continue
+			}
+			cidr, err := staticAddress(n, a)
+			if err != nil {
+				return fmt.Errorf("%s: network %s: %w", unit, j.Name, err)
+			}
+			sel.IPs = append(sel.IPs, cidr)
+		}
+		if len(sel.IPs) > 0 && !staticIPCapable(n, opts) {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: static addresses on network %s require host-local IPAM; ignoring %s.\n", sanitize(unit), sanitize(j.Name), sanitize(strings.Join(sel.IPs, ", ")))
+			sel.IPs = nil
+		}
+		if mac := j.Options["mac"]; mac != "" {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: mac=%s on network %s is not mapped.\n", sanitize(unit), sanitize(mac), sanitize(j.Name))
+		}
+		selections = append(selections, sel)
+	}
+
+	data, err := json.Marshal(selections)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string, len(template.Annotations)+1)
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+	annotations[multusNetworksAnnotation] = string(data)
+	template.Annotations = annotations
+	return nil
+}
+
+// staticAddress returns an address in CIDR notation, taking the prefix length
+// from the network's subnet containing it.
+func staticAddress(n *quadlet.NetworkUnit, s string) (string, error) {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q", s)
+	}
+	for _, subnet := range n.Network.Subnet {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err == nil && prefix.Masked().Contains(addr) {
+			return netip.PrefixFrom(addr, prefix.Bits()).String(), nil
+		}
+	}
+	return "", fmt.Errorf("address %s is not in any Subnet=", s)
+}
+
+func staticIPCapable(n *quadlet.NetworkUnit, opts Options) bool {
+	switch strings.ToLower(n.Network.IPAMDriver) {
+	case "":
+		return opts.MultusIPAM == IPAMHostLocal
+	case "host-local":
+		return true
+	}
+	return false
+}
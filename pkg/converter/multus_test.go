@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"encoding/json"
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConvertNetwork_MultusBridge(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{
+		"lan": "[Network]\nSubnet=10.89.0.0/24\nGateway=10.89.0.1\nIPRange=10.89.0.128/25\nOptions=mtu=1400\n",
+	})
+
+	objs, err := ConvertNetwork(networks["lan"], "lan", &Options{Multus: true, Networks: networks})
+	if err != nil {
+		t.Fatalf("ConvertNetwork failed: %v", err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("Expected only the NetworkAttachmentDefinition, got %d objects", len(objs))
+	}
+	nad, ok := objs[0].(*unstructured.Unstructured)
+	if !ok || nad.GetKind() != "NetworkAttachmentDefinition" || nad.GetName() != "lan" {
+		t.Fatalf("Unexpected object: %+v", objs[0])
+	}
+
+	raw, _, _ := unstructured.NestedString(nad.Object, "spec", "config")
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		t.Fatalf("Invalid CNI config %q: %v", raw, err)
+	}
+	if config["type"] != "bridge" || config["bridge"] != "lan" || config["mtu"] != float64(1400) {
+		t.Errorf("Unexpected plugin config: %v", config)
+	}
+	ipam := config["ipam"].(map[string]interface{})
+	if ipam["type"] != "host-local" {
+		t.Fatalf("Expected host-local IPAM, got %v", ipam)
+	}
+	r := ipam["ranges"].([]interface{})[0].([]interface{})[0].(map[string]interface{})
+	if r["subnet"] != "10.89.0.0/24" || r["gateway"] != "10.89.0.1" || r["rangeStart"] != "10.89.0.128" || r["rangeEnd"] != "10.89.0.255" {
+		t.Errorf("Unexpected range: %v", r)
+	}
+}
+
+func TestConvertNetwork_MultusMacvlanWhereabouts(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{
+		"dmz": "[Network]\nDriver=macvlan\nOptions=parent=eth1\nSubnet=192.168.10.0/24\nIPRange=192.168.10.50-192.168.10.60\n",
+	})
+
+	objs, err := ConvertNetwork(networks["dmz"], "dmz", &Options{Multus: true, MultusIPAM: IPAMWhereabouts, Networks: networks})
+	if err != nil {
+		t.Fatalf("ConvertNetwork failed: %v", err)
+	}
+	raw, _, _ := unstructured.NestedString(objs[0].(*unstructured.Unstructured).Object, "spec", "config")
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		t.Fatalf("Invalid CNI config %q: %v", raw, err)
+	}
+	if config["type"] != "macvlan" || config["master"] != "eth1" {
+		t.Errorf("Unexpected plugin config: %v", config)
+	}
+	ipam := config["ipam"].(map[string]interface{})
+	if ipam["type"] != "whereabouts" || ipam["range"] != "192.168.10.0/24" || ipam["range_start"] != "192.168.10.50" || ipam["range_end"] != "192.168.10.60" {
+		t.Errorf("Unexpected IPAM: %v", ipam)
+	}
+}
+
+func TestConvertNetwork_MultusRequiresSubnet(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{"lan": "[Network]\n"})
+
+	_, err := ConvertNetwork(networks["lan"], "lan", &Options{Multus: true, Networks: networks})
+	if err == nil || !strings.Contains(err.Error(), "Subnet=") {
+		t.Errorf("Expected error about missing Subnet=, got %v", err)
+	}
+}
+
+func TestConvertContainer_MultusStaticIP(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{
+		"lan": "[Network]\nSubnet=10.89.0.0/24\n",
+	})
+
+	input := `
+[Container]
+Image=db
+Network=lan.network
+IP=10.89.0.5
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "db", nil, &Options{Multus: true, Networks: networks})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	deployment := objs[0].(*appsv1.Deployment)
+	got := deployment.Spec.Template.Annotations["k8s.v1.cni.cncf.io/networks"]
+	if got != `[{"name":"lan","ips":["10.89.0.5/24"]}]` {
+		t.Errorf("Unexpected networks annotation: %s", got)
+	}
+}
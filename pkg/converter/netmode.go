@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyNetworkMode maps the Network= modes that change the pod's network
+// namespace: host shares the node's network, none has no Kubernetes
+// equivalent.
+func applyNetworkMode(unit string, spec *corev1.PodSpec, values []string) {
+	for _, v := range values {
+		mode, _, _ := strings.Cut(v, ":")
+		switch {
+		case mode == "host":
+			spec.HostNetwork = true
+			// Keep resolving cluster names from the host network.
+			spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+		case mode == "none":
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Network=none has no Kubernetes equivalent; the pod keeps its cluster network interface.\n", sanitize(unit))
+		case mode == "ns":
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Network=%s joins an existing network namespace, which is not supported; ignoring.\n", sanitize(unit), sanitize(v))
+		case mode == "pasta" || mode == "slirp4netns":
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Network=%s is a rootless networking mode; the pod uses the cluster network instead.\n", sanitize(unit), sanitize(v))
+		}
+	}
+}
+
+// ContainerPod returns the pod a container belongs to, either through Pod=
+// or through Network=pod:<name>, without the .pod suffix.
+func ContainerPod(c *quadlet.ContainerUnit) string {
+	if c.Container.Pod != "" {
+		return strings.TrimSuffix(c.Container.Pod, ".pod")
+	}
+	for _, v := range c.Container.Network {
+		if pod, ok := strings.CutPrefix(v, "pod:"); ok {
+			return strings.TrimSuffix(pod, ".pod")
+		}
+	}
+	return ""
+}
+
+// sharedNamespaceTarget returns the unit whose network namespace a container
+// joins via Network=container:<name> or Network=<name>.container. Container
+// names match a unit's ContainerName=, the unit name or Quadlet's default
+// systemd-<unit>. A reference to a container outside the conversion is
+// returned as unresolved.
+func sharedNamespaceTarget(unit string, c *quadlet.ContainerUnit, containers map[string]*quadlet.ContainerUnit) (target string, unresolved string) {
+	for _, v := range c.Container.Network {
+		ref, isContainer := strings.CutPrefix(v, "container:")
+		if isContainer {
+			if name := containerNameUnit(ref, containers); name != "" && name != unit {
+				return name, ""
+			}
+		} else {
+			r, _, _ := strings.Cut(v, ":")
+			if ref, isContainer = strings.CutSuffix(r, ".container"); !isContainer {
+				continue
+			}
+		}
+		for _, candidate := range []string{ref, strings.TrimPrefix(ref, "systemd-")} {
+			if _, ok := containers[candidate]; ok && candidate != unit {
+				return candidate, ""
+			}
+		}
+		unresolved = v
+	}
+	return "", unresolved
+}
+
+// containerNameUnit returns the unit whose ContainerName= is name, or "".
+func containerNameUnit(name string, containers map[string]*quadlet.ContainerUnit) string {
+	units := make([]string, 0, len(containers))
+	for unit := range containers {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	for _, unit := range units {
+		if containers[unit].Container.ContainerName == name {
+			return unit
+		}
+	}
+	return ""
+}
+
+// sharedNamespaceOwner follows Network=container: references to the container
+// owning the network namespace. It returns the unit itself if it joins no
+// other container.
+func sharedNamespaceOwner(unit string, c *quadlet.ContainerUnit, containers map[string]*quadlet.ContainerUnit) (string, error) {
+	seen := map[string]bool{unit: true}
+	owner := unit
+	for {
+		target, _ := sharedNamespaceTarget(owner, c, containers)
+		if target == "" {
+			return owner, nil
+		}
+		if seen[target] {
+			return "", fmt.Errorf("container %s: Network=container: references form a cycle", unit)
+		}
+		seen[target] = true
+		owner = target
+		c = containers[target]
+	}
+}
+
+// sharedNamespaceMembers returns, sorted by name, the containers whose network
+// namespace is owned by unit. They are co-located into its Deployment.
+func sharedNamespaceMembers(unit string, containers map[string]*quadlet.ContainerUnit) ([]string, error) {
+	var members []string
+	for name, c := range containers {
+		if name == unit || ContainerPod(c) != "" {
+			continue
+		}
+		owner, err := sharedNamespaceOwner(name, c, containers)
+		if err != nil {
+			return nil, err
+		}
+		if owner == unit {
+			members = append(members, name)
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// onUserNetwork reports whether Network= attaches a unit to a user-defined
+// network, where Podman's DNS resolves container names and aliases. Networks
+// are user-defined unless they select a mode or another namespace.
+func onUserNetwork(values []string) bool {
+	for _, v := range values {
+		ref, _, _ := strings.Cut(v, ":")
+		if networkModes[ref] || strings.HasSuffix(ref, ".container") {
+			continue
+		}
+		switch ref {
+		case "container", "ns", "pod":
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// networkAliases returns the alias= options of Network= entries.
+func networkAliases(values []string) []string {
+	var aliases []string
+	for _, v := range values {
+		_, opts, _ := strings.Cut(v, ":")
+		for _, opt := range strings.Split(opts, ",") {
+			if a, ok := strings.CutPrefix(opt, "alias="); ok && a != "" {
+				aliases = append(aliases, a)
+			}
+		}
+	}
+	return aliases
+}
@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func loadContainers(t *testing.T, inputs map[string]string) map[string]*quadlet.ContainerUnit {
+	t.Helper()
+	containers := make(map[string]*quadlet.ContainerUnit)
+	for name, input := range inputs {
+		unit, err := parser.Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		containers[name] = quadlet.LoadContainer(unit)
+	}
+	return containers
+}
+
+func TestConvertContainer_HostNetwork(t *testing.T) {
+	input := `
+[Container]
+Image=exporter
+Network=host
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "exporter", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	if !spec.HostNetwork {
+		t.Error("Expected hostNetwork: true")
+	}
+	if spec.DNSPolicy != corev1.DNSClusterFirstWithHostNet {
+		t.Errorf("Expected dnsPolicy ClusterFirstWithHostNet, got %q", spec.DNSPolicy)
+	}
+}
+
+func TestConvertContainer_SharedNetworkNamespace(t *testing.T) {
+	containers := loadContainers(t, map[string]string{
+		"app":   "[Container]\nImage=app\nPublishPort=8080:8080\n",
+		"proxy": "[Container]\nImage=envoy\nNetwork=app.container\n",
+		"agent": "[Container]\nImage=agent\nNetwork=container:systemd-proxy\n",
+	})
+	opts := &Options{Containers: containers}
+
+	// Members are converted as part of the namespace owner.
+	for _, member := range []string{"proxy", "agent"} {
+		objs, err := ConvertContainer(containers[member], member, nil, opts)
+		if err != nil {
+			t.Fatalf("ConvertContainer(%s) failed: %v", member, err)
+		}
+		if len(objs) != 0 {
+			t.Errorf("Expected no objects for %s, got %d", member, len(objs))
+		}
+	}
+
+	objs, err := ConvertContainer(containers["app"], "app", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	got := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers
+	if len(got) != 3 || got[0].Name != "app" || got[1].Name != "agent" || got[2].Name != "proxy" {
+		t.Errorf("Expected app, agent and proxy in one pod, got %+v", got)
+	}
+}
+
+func TestConvertContainer_SharedNetworkNamespaceContainerName(t *testing.T) {
+	containers := loadContainers(t, map[string]string{
+		"app":     "[Container]\nImage=app\nContainerName=web\n",
+		"sidecar": "[Container]\nImage=envoy\nNetwork=container:web\n",
+	})
+	opts := &Options{Containers: containers}
+
+	objs, err := ConvertContainer(containers["sidecar"], "sidecar", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer(sidecar) failed: %v", err)
+	}
+	if len(objs) != 0 {
+		t.Errorf("Expected no objects for sidecar, got %d", len(objs))
+	}
+
+	objs, err = ConvertContainer(containers["app"], "app", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	got := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers
+	if len(got) != 2 || got[1].Name != "sidecar" {
+		t.Errorf("Expected app and sidecar in one pod, got %+v", got)
+	}
+}
+
+func TestConvertPod_SharedNetworkNamespaceMember(t *testing.T) {
+	containers := loadContainers(t, map[string]string{
+		"app":  "[Container]\nImage=app\nPod=stack.pod\n",
+		"side": "[Container]\nImage=side\nNetwork=container:systemd-app\n",
+	})
+	opts := &Options{Containers: containers}
+
+	objs, err := ConvertContainer(containers["side"], "side", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer(side) failed: %v", err)
+	}
+	if len(objs) != 0 {
+		t.Errorf("Expected no objects for side, got %d", len(objs))
+	}
+
+	unit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	pod := quadlet.LoadPod(unit)
+	objs, err = ConvertPod(pod, []*quadlet.ContainerUnit{containers["app"]}, []string{"app"}, "stack", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	got := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers
+	if len(got) != 2 || got[0].Name != "app" || got[1].Name != "side" {
+		t.Errorf("Expected app and side in the stack pod, got %+v", got)
+	}
+}
+
+func TestConvertContainer_SharedNetworkNamespaceCycle(t *testing.T) {
+	containers := loadContainers(t, map[string]string{
+		"a": "[Container]\nImage=a\nNetwork=b.container\n",
+		"b": "[Container]\nImage=b\nNetwork=a.container\n",
+	})
+
+	_, err := ConvertContainer(containers["a"], "a", nil, &Options{Containers: containers})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected cycle error, got %v", err)
+	}
+}
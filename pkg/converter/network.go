@@ -0,0 +1,232 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// networkLabelPrefix marks pod templates with the networks they join, e.g.
+// network.kuadlet.io/backend: "true". A unit may join several networks, so
+// each membership gets its own key.
+const networkLabelPrefix = "network.kuadlet.io/"
+
+func networkLabel(network string) string {
+	return networkLabelPrefix + network
+}
+
+// networkModes are Network= values that select a mode rather than a
+// user-defined network.
+var networkModes = map[string]bool{
+	"host":        true,
+	"none":        true,
+	"private":     true,
+	"bridge":      true,
+	"slirp4netns": true,
+	"pasta":       true,
+}
+
+// resolveNetwork returns the unit name of the .network unit a Network= value
+// refers to. Values may be "name.network", a network name matching a unit's
+// file name or NetworkName, optionally followed by ":options".
+func resolveNetwork(value string, networks map[string]*quadlet.NetworkUnit) (string, bool) {
+	ref, _, _ := strings.Cut(value, ":")
+	if networkModes[ref] || strings.HasPrefix(value, "container:") || strings.HasPrefix(value, "ns:") || strings.HasPrefix(value, "pod:") {
+		return "", false
+	}
+
+	if unitName, ok := strings.CutSuffix(ref, ".network"); ok {
+		if _, found := networks[unitName]; found {
+			return unitName, true
+		}
+		return "", false
+	}
+	if _, found := networks[ref]; found {
+		return ref, true
+	}
+	for unitName, n := range networks {
+		if n.Network.NetworkName == ref {
+			return unitName, true
+		}
+	}
+	return "", false
+}
+
+// joinedNetwork is a user-defined network a unit joins, with the per-network
+// options of its Network= entry (e.g. ip=10.0.0.5,alias=db).
+type joinedNetwork struct {
+	Name    string
+	Options map[string]string
+}
+
+// unitNetworks resolves the user-defined networks a unit joins, warning about
+// references to networks that are not part of the conversion.
+func unitNetworks(unit string, values []string, networks map[string]*quadlet.NetworkUnit) []joinedNetwork {
+	var result []joinedNetwork
+	seen := make(map[string]bool)
+	for _, v := range values {
+		n, ok := resolveNetwork(v, networks)
+		if !ok {
+			ref, _, _ := strings.Cut(v, ":")
+			if !networkModes[ref] && !strings.HasPrefix(v, "container:") && !strings.HasPrefix(v, "ns:") && !strings.HasPrefix(v, "pod:") && !strings.HasSuffix(ref, ".container") {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: Network=%s does not match any converted .network unit; ignoring.\n", sanitize(unit), sanitize(v))
+			}
+			continue
+		}
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+
+		joined := joinedNetwork{Name: n, Options: make(map[string]string)}
+		if _, opts, ok := strings.Cut(v, ":"); ok {
+			for _, opt := range strings.Split(opts, ",") {
+				if k, val, ok := strings.Cut(opt, "="); ok {
+					joined.Options[k] = val
+				}
+			}
+		}
+		result = append(result, joined)
+	}
+	return result
+}
+
+// applyNetworkMembership labels a pod template with its networks and returns
+// a NetworkPolicy that keeps published ports reachable from outside the
+// networks. It does nothing unless NetworkPolicies are enabled.
+func applyNetworkMembership(name string, template *corev1.PodTemplateSpec, joined []joinedNetwork, published *publishedPorts, opts Options) []runtime.Object {
+	if !opts.NetworkPolicies || len(joined) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(template.Labels)+len(joined))
+	for k, v := range template.Labels {
+		labels[k] = v
+	}
+	for _, n := range joined {
+		labels[networkLabel(n.Name)] = "true"
+	}
+	template.Labels = labels
+
+	if published == nil || len(published.Service) == 0 {
+		return nil
+	}
+
+	// Published ports are reachable from anywhere on the host; keep them
+	// reachable from outside the network in the cluster too.
+	var ports []networkingv1.NetworkPolicyPort
+	for _, sp := range published.Service {
+		protocol := sp.Protocol
+		port := intstr.FromInt32(sp.TargetPort.IntVal)
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+
+	return []runtime.Object{&networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-published", name),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app.kubernetes.io/name": name},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{Ports: ports},
+			},
+		},
+	}}
+}
+
+// networkPolicies builds the policies isolating the members of a network:
+// ingress is only allowed from other members, and for Internal=true networks
+// egress is limited to members and cluster DNS.
+func networkPolicies(n *quadlet.NetworkUnit, name string, opts Options) []runtime.Object {
+	member := metav1.LabelSelector{
+		MatchLabels: map[string]string{networkLabel(name): "true"},
+	}
+
+	objects := []runtime.Object{&networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-ingress", name),
+			Labels: n.Network.Label,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: member,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{From: []networkingv1.NetworkPolicyPeer{{PodSelector: member.DeepCopy()}}},
+			},
+		},
+	}}
+
+	if !n.Network.Internal {
+		return objects
+	}
+
+	// Pods that also join a non-internal network may still leave the group
+	// through it, so they are excluded from the egress restriction.
+	selector := member.DeepCopy()
+	var external []string
+	for other, o := range opts.Networks {
+		if other != name && !o.Network.Internal {
+			external = append(external, other)
+		}
+	}
+	sort.Strings(external)
+	for _, other := range external {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      networkLabel(other),
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		})
+	}
+
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	dnsPort := intstr.FromInt32(53)
+	objects = append(objects, &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-egress", name),
+			Labels: n.Network.Label,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *selector,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{{PodSelector: member.DeepCopy()}}},
+				{
+					To: []networkingv1.NetworkPolicyPeer{{
+						NamespaceSelector: &metav1.LabelSelector{},
+						PodSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+						},
+					}},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: &udp, Port: &dnsPort},
+						{Protocol: &tcp, Port: &dnsPort},
+					},
+				},
+			},
+		},
+	})
+	return objects
+}
@@ -0,0 +1,115 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func loadNetworks(t *testing.T, inputs map[string]string) map[string]*quadlet.NetworkUnit {
+	t.Helper()
+	networks := make(map[string]*quadlet.NetworkUnit)
+	for name, input := range inputs {
+		unit, err := parser.Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		networks[name] = quadlet.LoadNetwork(unit)
+	}
+	return networks
+}
+
+func TestConvertNetwork_Policies(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{
+		"backend":  "[Network]\nInternal=true\n",
+		"frontend": "[Network]\n",
+	})
+	opts := &Options{NetworkPolicies: true, Networks: networks}
+
+	objs, err := ConvertNetwork(networks["backend"], "backend", opts)
+	if err != nil {
+		t.Fatalf("ConvertNetwork failed: %v", err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("Expected ingress and egress policies, got %d objects", len(objs))
+	}
+
+	ingress := objs[0].(*networkingv1.NetworkPolicy)
+	if ingress.Spec.PodSelector.MatchLabels["network.kuadlet.io/backend"] != "true" {
+		t.Errorf("Unexpected ingress podSelector: %+v", ingress.Spec.PodSelector)
+	}
+	if len(ingress.Spec.Ingress) != 1 || ingress.Spec.Ingress[0].From[0].PodSelector.MatchLabels["network.kuadlet.io/backend"] != "true" {
+		t.Errorf("Expected ingress only from backend members, got %+v", ingress.Spec.Ingress)
+	}
+
+	egress := objs[1].(*networkingv1.NetworkPolicy)
+	if egress.Spec.PolicyTypes[0] != networkingv1.PolicyTypeEgress {
+		t.Errorf("Expected egress policy, got %v", egress.Spec.PolicyTypes)
+	}
+	// Members that also join the non-internal frontend network keep their egress.
+	exprs := egress.Spec.PodSelector.MatchExpressions
+	if len(exprs) != 1 || exprs[0].Key != "network.kuadlet.io/frontend" || exprs[0].Operator != metav1.LabelSelectorOpDoesNotExist {
+		t.Errorf("Unexpected egress podSelector expressions: %+v", exprs)
+	}
+
+	objs, err = ConvertNetwork(networks["frontend"], "frontend", opts)
+	if err != nil {
+		t.Fatalf("ConvertNetwork failed: %v", err)
+	}
+	if len(objs) != 1 {
+		t.Errorf("Expected only an ingress policy for a non-internal network, got %d objects", len(objs))
+	}
+}
+
+func TestConvertContainer_NetworkMembership(t *testing.T) {
+	networks := loadNetworks(t, map[string]string{
+		"backend": "[Network]\nNetworkName=backend-net\n",
+	})
+
+	input := `
+[Container]
+Image=api
+Network=backend-net
+PublishPort=8080:80
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "api", nil, &Options{NetworkPolicies: true, Networks: networks})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	var deployment *appsv1.Deployment
+	var published *networkingv1.NetworkPolicy
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deployment = o
+		case *networkingv1.NetworkPolicy:
+			published = o
+		}
+	}
+
+	if deployment == nil {
+		t.Fatal("Deployment not found")
+	}
+	if deployment.Spec.Template.Labels["network.kuadlet.io/backend"] != "true" {
+		t.Errorf("Expected network membership label on pod template, got %v", deployment.Spec.Template.Labels)
+	}
+	if _, ok := deployment.Spec.Selector.MatchLabels["network.kuadlet.io/backend"]; ok {
+		t.Error("Network membership must not be part of the selector")
+	}
+
+	if published == nil {
+		t.Fatal("Expected NetworkPolicy for published ports")
+	}
+	if published.Spec.Ingress[0].Ports[0].Port.IntVal != 80 {
+		t.Errorf("Expected published container port 80 to be allowed, got %v", published.Spec.Ingress[0].Ports)
+	}
+}
@@ -0,0 +1,160 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Options holds conversion settings that are not expressed in the units
+// themselves. A nil *Options selects the defaults.
+type Options struct {
+	// ServiceType is the exposure used for units that don't set
+	// kuadlet.io/service-type. Defaults to ClusterIP.
+	ServiceType ServiceType
+
+	// NodePortMin and NodePortMax bound the cluster's node port range.
+	// Defaults to 30000-32767.
+	NodePortMin int32
+	NodePortMax int32
+
+	// Exposure selects the routing resources generated for units with HTTP
+	// routes. Defaults to none.
+	Exposure Exposure
+	// IngressClassName is set on generated Ingresses when non-empty.
+	IngressClassName string
+	// GatewayName and GatewayNamespace form the parentRef of generated
+	// HTTPRoutes.
+	GatewayName      string
+	GatewayNamespace string
+
+	// NativeProbes translates health commands that curl, wget or nc
+	// localhost into httpGet and tcpSocket probes. These reach the app on
+	// the pod IP instead of loopback.
+	NativeProbes bool
+
+	// NetworkPolicies translates .network units into NetworkPolicies that
+	// isolate their members.
+	NetworkPolicies bool
+
+	// Multus translates .network units into Multus NetworkAttachmentDefinitions
+	// and attaches their members as secondary interfaces.
+	Multus bool
+	// MultusIPAM is the IPAM plugin of networks without IPAMDriver=. Defaults
+	// to host-local.
+	MultusIPAM string
+
+	// Networks holds the loaded .network units by unit name, for resolving
+	// Network= references.
+	Networks map[string]*quadlet.NetworkUnit
+	// Containers holds the loaded .container units by unit name, for
+	// co-locating containers that share a network namespace.
+	Containers map[string]*quadlet.ContainerUnit
+	// Images and Builds hold the loaded .image and .build units by unit name,
+	// for resolving Image= references.
+	Images map[string]*quadlet.ImageUnit
+	Builds map[string]*quadlet.BuildUnit
+
+	// QualifyImages fully qualifies short image names through the aliases
+	// and search registries of Registries, defaulting to docker.io, so nodes
+	// don't depend on their own short-name resolution. RegistryRewrites then
+	// replace registry prefixes, e.g. with an internal mirror, and imply
+	// QualifyImages.
+	QualifyImages    bool
+	Registries       RegistriesConf
+	RegistryRewrites []RegistryRewrite
+
+	// Passwd and Groups resolve user and group names in User=, Group= and
+	// GroupAdd=, typically loaded from the image's /etc/passwd and /etc/group.
+	Passwd map[string]PasswdEntry
+	Groups map[string]int64
+
+	// DeviceResources maps host device paths, or directories of devices, to
+	// the extended resources of the device plugins exposing them.
+	DeviceResources map[string]string
+
+	// PullSecrets generates kubernetes.io/dockerconfigjson Secrets from the
+	// AuthFile= or Creds= of .image units and references them from pods
+	// pulling from the same registries. Redact emits placeholder credentials.
+	// PullSecretsByRegistry holds the Secrets built once by BuildPullSecrets,
+	// keyed by the registry they authenticate to; pods reference only these.
+	PullSecrets           bool
+	Redact                bool
+	PullSecretsByRegistry map[string][]*corev1.Secret
+
+	// AutoUpdateAnnotations are set on Deployments with a container using
+	// AutoUpdate=registry, for image automation such as Keel.
+	AutoUpdateAnnotations map[string]string
+
+	// KubeVersion is the target cluster's version, enabling fields newer
+	// releases support. Defaults to the most portable output.
+	KubeVersion KubeVersion
+
+	// RequestRatio is the fraction of a CPU or memory limit requested when a
+	// unit sets no reservation. Defaults to 1, requests equal to limits.
+	RequestRatio float64
+
+	// PodSecurity selects the Pod Security Standard generated pod templates
+	// are checked against. Defaults to no checks.
+	PodSecurity PodSecurityLevel
+	// Harden adds the restricted standard's secure defaults where the units
+	// don't say otherwise.
+	Harden bool
+}
+
+func (o *Options) withDefaults() Options {
+	var r Options
+	if o != nil {
+		r = *o
+	}
+	if r.ServiceType == "" {
+		r.ServiceType = ServiceTypeClusterIP
+	}
+	if r.NodePortMin == 0 && r.NodePortMax == 0 {
+		r.NodePortMin = 30000
+		r.NodePortMax = 32767
+	}
+	if r.RequestRatio == 0 {
+		r.RequestRatio = 1
+	}
+	if r.MultusIPAM == "" {
+		r.MultusIPAM = IPAMHostLocal
+	}
+	if len(r.RegistryRewrites) > 0 {
+		r.QualifyImages = true
+	}
+	return r
+}
+
+// ServiceType selects how the Service generated for a unit is exposed.
+type ServiceType string
+
+const (
+	ServiceTypeClusterIP    ServiceType = "ClusterIP"
+	ServiceTypeNodePort     ServiceType = "NodePort"
+	ServiceTypeLoadBalancer ServiceType = "LoadBalancer"
+	ServiceTypeHeadless     ServiceType = "Headless"
+)
+
+// ParseServiceType parses a service type name case-insensitively.
+func ParseServiceType(s string) (ServiceType, error) {
+	for _, t := range []ServiceType{ServiceTypeClusterIP, ServiceTypeNodePort, ServiceTypeLoadBalancer, ServiceTypeHeadless} {
+		if strings.EqualFold(s, string(t)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown service type %q (expected ClusterIP, NodePort, LoadBalancer or Headless)", s)
+}
+
+func (t ServiceType) kubernetesType() corev1.ServiceType {
+	switch t {
+	case ServiceTypeNodePort:
+		return corev1.ServiceTypeNodePort
+	case ServiceTypeLoadBalancer:
+		return corev1.ServiceTypeLoadBalancer
+	default:
+		return corev1.ServiceTypeClusterIP
+	}
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// containerDNSName is the name Podman's DNS resolves a container by on a
+// user-defined network: ContainerName, or Quadlet's default systemd-<unit>.
+func containerDNSName(c *quadlet.ContainerUnit, unit string) string {
+	if c.Container.ContainerName != "" {
+		return c.Container.ContainerName
+	}
+	return "systemd-" + unit
+}
+
+// podDNSName is the name of a Quadlet pod on its networks.
+func podDNSName(p *quadlet.PodUnit, unit string) string {
+	if p.Pod.PodName != "" {
+		return p.Pod.PodName
+	}
+	return "systemd-" + unit
+}
+
+// peerPorts lists the ports declared by the containers of a pod spec. Peers
+// on a Podman network connect to container ports, not published host ports.
+func peerPorts(spec *corev1.PodSpec) []corev1.ServicePort {
+	var ports []corev1.ServicePort
+	seen := make(map[portKey]bool)
+	for _, c := range spec.Containers {
+		for _, p := range c.Ports {
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			if k := (portKey{p.ContainerPort, protocol}); !seen[k] {
+				seen[k] = true
+				ports = append(ports, corev1.ServicePort{
+					Name:       fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), p.ContainerPort),
+					Port:       p.ContainerPort,
+					TargetPort: intstr.FromInt32(p.ContainerPort),
+					Protocol:   protocol,
+				})
+			}
+		}
+	}
+	return ports
+}
+
+// peerServices creates a Service per DNS name a unit is reachable by on its
+// Podman networks, so peers keep resolving e.g. http://db:5432. A name equal
+// to the unit's own Service adds the container ports to it instead. Names
+// taken by other Services, or not valid Service names, are skipped. Without
+// ports the Service is headless; its DNS name still resolves to the pod IPs.
+func peerServices(unit string, dnsNames []string, labels map[string]string, spec *corev1.PodSpec, serviceType ServiceType, own *corev1.Service, taken map[string]bool) []runtime.Object {
+	ports := peerPorts(spec)
+
+	var objects []runtime.Object
+	seen := make(map[string]bool)
+	for _, n := range dnsNames {
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		if own != nil && n == own.Name {
+			addPeerPorts(unit, own, ports)
+			continue
+		}
+		if taken[n] {
+			continue
+		}
+		if errs := validation.IsDNS1035Label(n); len(errs) > 0 {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: DNS name %s is not a valid Service name (%s); peers cannot resolve it.\n", sanitize(unit), sanitize(n), sanitize(strings.Join(errs, "; ")))
+			continue
+		}
+
+		service := &corev1.Service{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Service",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   n,
+				Labels: labels,
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    ports,
+				Type:     corev1.ServiceTypeClusterIP,
+			},
+		}
+		if len(ports) == 0 || serviceType == ServiceTypeHeadless {
+			service.Spec.ClusterIP = corev1.ClusterIPNone
+		}
+		objects = append(objects, service)
+	}
+	return objects
+}
+
+// addPeerPorts serves the container ports on the unit's own Service when its
+// name is also a peer DNS name. A published port already using the same
+// number keeps it.
+func addPeerPorts(unit string, service *corev1.Service, ports []corev1.ServicePort) {
+	for _, p := range ports {
+		i := slices.IndexFunc(service.Spec.Ports, func(sp corev1.ServicePort) bool {
+			return sp.Port == p.Port && sp.Protocol == p.Protocol
+		})
+		if i < 0 {
+			service.Spec.Ports = append(service.Spec.Ports, p)
+			continue
+		}
+		if target := service.Spec.Ports[i].TargetPort.IntVal; target != p.Port {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Service %s publishes port %d to %d; peers connecting to container port %d reach %d instead.\n", sanitize(unit), sanitize(service.Name), p.Port, target, p.Port, target)
+		}
+	}
+}
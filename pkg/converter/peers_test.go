@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertContainer_PeerServices(t *testing.T) {
+	input := `
+[Container]
+Image=postgres
+ContainerName=db
+Network=backend.network:alias=primary
+NetworkAlias=postgres
+ExposeHostPort=5432
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "database", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	services := make(map[string]*corev1.Service)
+	for _, obj := range objs {
+		if s, ok := obj.(*corev1.Service); ok {
+			services[s.Name] = s
+		}
+	}
+	if len(services) != 3 {
+		t.Fatalf("Expected Services db, postgres and primary, got %v", services)
+	}
+	for _, n := range []string{"db", "postgres", "primary"} {
+		s := services[n]
+		if s == nil {
+			t.Fatalf("Service %s not found", n)
+		}
+		if s.Spec.Selector["app.kubernetes.io/name"] != "database" {
+			t.Errorf("Service %s: unexpected selector %v", n, s.Spec.Selector)
+		}
+		if len(s.Spec.Ports) != 1 || s.Spec.Ports[0].Port != 5432 || s.Spec.Ports[0].TargetPort.IntVal != 5432 {
+			t.Errorf("Service %s: expected port 5432, got %+v", n, s.Spec.Ports)
+		}
+	}
+}
+
+func TestConvertContainer_PeerPortsOnOwnService(t *testing.T) {
+	input := `
+[Container]
+Image=postgres
+ContainerName=db
+Network=backend.network
+PublishPort=15432:5432
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "db", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	var services []*corev1.Service
+	for _, obj := range objs {
+		if s, ok := obj.(*corev1.Service); ok {
+			services = append(services, s)
+		}
+	}
+	if len(services) != 1 || services[0].Name != "db" {
+		t.Fatalf("Expected the single Service db, got %v", services)
+	}
+	ports := make(map[int32]int32)
+	for _, p := range services[0].Spec.Ports {
+		ports[p.Port] = p.TargetPort.IntVal
+	}
+	if ports[15432] != 5432 {
+		t.Errorf("Expected published port 15432 -> 5432, got %+v", services[0].Spec.Ports)
+	}
+	if ports[5432] != 5432 {
+		t.Errorf("Expected peer port 5432 -> 5432, got %+v", services[0].Spec.Ports)
+	}
+}
+
+func TestConvertContainer_PeerServicesWithoutPorts(t *testing.T) {
+	input := `
+[Container]
+Image=worker
+Network=backend
+PublishPort=127.0.0.1:9000:9000
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "worker", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	service := findService(objs)
+	if service == nil || service.Name != "systemd-worker" {
+		t.Fatalf("Expected Service systemd-worker, got %+v", service)
+	}
+	// The loopback-bound port is not published, but peers on the network
+	// still reach the container port.
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Port != 9000 {
+		t.Errorf("Expected port 9000, got %+v", service.Spec.Ports)
+	}
+}
+
+func TestConvertContainer_NoPeerServicesOnHostNetwork(t *testing.T) {
+	input := `
+[Container]
+Image=app
+Network=host
+NetworkAlias=app
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if service := findService(objs); service != nil {
+		t.Errorf("Expected no Service, got %s", service.Name)
+	}
+}
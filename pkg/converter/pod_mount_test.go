@@ -29,7 +29,7 @@ Image=alpine
 	containers := []*quadlet.ContainerUnit{qContainer}
 	names := []string{"app"}
 
-	objs, err := ConvertPod(qPod, containers, names, "test-pod", nil)
+	objs, err := ConvertPod(qPod, containers, names, "test-pod", nil, nil)
 	if err != nil {
 		t.Fatalf("ConvertPod failed: %v", err)
 	}
@@ -0,0 +1,278 @@
+package converter
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// maxPortRange bounds the ports a single PublishPort range expands to; each
+// becomes a containerPort and a Service port.
+const maxPortRange = 256
+
+// portMapping is a single host-to-container port binding expanded from a
+// PublishPort entry.
+type portMapping struct {
+	HostIP        string
+	HostPort      int32
+	ContainerPort int32
+	Protocol      corev1.Protocol
+}
+
+// loopback reports whether the mapping is only reachable from the host itself.
+func (m portMapping) loopback() bool {
+	ip := net.ParseIP(m.HostIP)
+	return ip != nil && ip.IsLoopback()
+}
+
+// parsePortSpec parses a PublishPort value using Podman's grammar:
+//
+//	[[ip:][hostPort]:]containerPort[/protocol]
+//
+// The IP may be an IPv4 address or a bracketed IPv6 address, and both ports
+// may be ranges (e.g. 8000-8010:8000-8010). Ranges are expanded into one
+// mapping per port, up to maxPortRange ports.
+func parsePortSpec(spec string) ([]portMapping, error) {
+	s := strings.TrimSpace(spec)
+	if s == "" {
+		return nil, fmt.Errorf("empty port specification")
+	}
+
+	protocol := corev1.ProtocolTCP
+	if idx := strings.LastIndex(s, "/"); idx != -1 {
+		switch strings.ToLower(s[idx+1:]) {
+		case "tcp":
+			protocol = corev1.ProtocolTCP
+		case "udp":
+			protocol = corev1.ProtocolUDP
+		case "sctp":
+			protocol = corev1.ProtocolSCTP
+		default:
+			return nil, fmt.Errorf("unsupported protocol %q", s[idx+1:])
+		}
+		s = s[:idx]
+	}
+
+	var hostIP string
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]")
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated IPv6 address")
+		}
+		hostIP = s[1:end]
+		rest := s[end+1:]
+		if !strings.HasPrefix(rest, ":") {
+			return nil, fmt.Errorf("expected ':' after IPv6 address")
+		}
+		s = rest[1:]
+		if ip := net.ParseIP(hostIP); ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid IPv6 address %q", hostIP)
+		}
+	}
+
+	var hostPart, containerPart string
+	parts := strings.Split(s, ":")
+	switch {
+	case len(parts) == 1 && hostIP == "":
+		containerPart = parts[0]
+	case len(parts) == 2:
+		hostPart = parts[0]
+		containerPart = parts[1]
+	case len(parts) == 3 && hostIP == "":
+		hostIP = parts[0]
+		hostPart = parts[1]
+		containerPart = parts[2]
+		if ip := net.ParseIP(hostIP); ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid IPv4 address %q (IPv6 addresses must be enclosed in brackets)", hostIP)
+		}
+	default:
+		return nil, fmt.Errorf("malformed port specification")
+	}
+
+	cStart, cEnd, err := parsePortRange(containerPart)
+	if err != nil {
+		return nil, fmt.Errorf("container port: %w", err)
+	}
+
+	// An empty or zero host port lets Podman pick one; a Service has to
+	// expose something, so fall back to the container port.
+	hStart, hEnd := cStart, cEnd
+	if hostPart != "" && hostPart != "0" {
+		hStart, hEnd, err = parsePortRange(hostPart)
+		if err != nil {
+			return nil, fmt.Errorf("host port: %w", err)
+		}
+		if hEnd-hStart != cEnd-cStart {
+			return nil, fmt.Errorf("host port range %s and container port range %s differ in length", hostPart, containerPart)
+		}
+	}
+
+	if n := cEnd - cStart + 1; n > maxPortRange {
+		return nil, fmt.Errorf("range of %d ports exceeds the limit of %d; use hostNetwork for large port ranges", n, maxPortRange)
+	}
+
+	var mappings []portMapping
+	for offset := int32(0); offset <= cEnd-cStart; offset++ {
+		mappings = append(mappings, portMapping{
+			HostIP:        hostIP,
+			HostPort:      hStart + offset,
+			ContainerPort: cStart + offset,
+			Protocol:      protocol,
+		})
+	}
+	return mappings, nil
+}
+
+// parsePortRange parses "port" or "start-end" and returns the inclusive bounds.
+func parsePortRange(s string) (int32, int32, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	start, err := parsePortNumber(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := parsePortNumber(endStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid port range %s", s)
+	}
+	return start, end, nil
+}
+
+func parsePortNumber(s string) (int32, error) {
+	p, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	if p < 1 || p > 65535 {
+		return 0, fmt.Errorf("port %d out of valid range (1-65535)", p)
+	}
+	return int32(p), nil // #nosec G109 G115 -- bounded above
+}
+
+// portName returns a unique, DNS-label compatible name for the n-th port of
+// the i-th PublishPort entry. Single-port entries keep the historical
+// "<prefix>-<i>" form.
+func portName(prefix string, i int, n int, total int) string {
+	if total == 1 {
+		return fmt.Sprintf("%s-%d", prefix, i)
+	}
+	return fmt.Sprintf("%s-%d-%d", prefix, i, n)
+}
+
+// portKey identifies a port for duplicate detection; the same number may be
+// used once per protocol.
+type portKey struct {
+	Port     int32
+	Protocol corev1.Protocol
+}
+
+// publishedPorts is the result of expanding a unit's PublishPort entries.
+type publishedPorts struct {
+	Container []corev1.ContainerPort
+	Service   []corev1.ServicePort
+	// HostIPs lists the specific, non-loopback addresses ports are bound to,
+	// in order of first appearance.
+	HostIPs []string
+}
+
+// expandPublishPorts parses the PublishPort entries of a unit. Port names are
+// derived from prefix; scope is appended to error messages (e.g. " in Pod")
+// and unit names the unit in warnings.
+func expandPublishPorts(specs []string, prefix string, scope string, unit string) (*publishedPorts, error) {
+	published := &publishedPorts{}
+
+	// Deduplication check for Service Ports
+	// Key: host port and protocol
+	seenServicePorts := make(map[portKey]string)
+	seenContainerPorts := make(map[portKey]bool)
+	seenHostIPs := make(map[string]bool)
+
+	for i, portSpec := range specs {
+		mappings, err := parsePortSpec(portSpec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PublishPort %q%s: %w", portSpec, scope, err)
+		}
+
+		for n, m := range mappings {
+			pName := portName(prefix, i, n, len(mappings))
+
+			// The same container port may be published several times; list it once.
+			if ck := (portKey{m.ContainerPort, m.Protocol}); !seenContainerPorts[ck] {
+				seenContainerPorts[ck] = true
+				published.Container = append(published.Container, corev1.ContainerPort{
+					Name:          pName,
+					ContainerPort: m.ContainerPort,
+					Protocol:      m.Protocol,
+				})
+			}
+
+			if m.loopback() {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: PublishPort %s of %s is bound to loopback address %s; it is not exposed by the Service.\n", sanitize(portSpec), sanitize(unit), m.HostIP)
+				continue
+			}
+
+			if ip := net.ParseIP(m.HostIP); ip != nil && !ip.IsUnspecified() && !seenHostIPs[m.HostIP] {
+				seenHostIPs[m.HostIP] = true
+				published.HostIPs = append(published.HostIPs, m.HostIP)
+			}
+
+			// Check for duplicate host port
+			sk := portKey{m.HostPort, m.Protocol}
+			if definedIn, ok := seenServicePorts[sk]; ok {
+				return nil, fmt.Errorf("duplicate port definition detected%s: port %d/%s is already defined in %s", scope, m.HostPort, m.Protocol, definedIn)
+			}
+			seenServicePorts[sk] = fmt.Sprintf("PublishPort index %d", i)
+
+			published.Service = append(published.Service, corev1.ServicePort{
+				Name:       pName,
+				Port:       m.HostPort,
+				TargetPort: intstr.FromInt32(m.ContainerPort),
+				Protocol:   m.Protocol,
+			})
+		}
+	}
+
+	return published, nil
+}
+
+// exposeHostPorts parses ExposeHostPort entries, port[-end][/protocol], into
+// container ports not already declared in existing.
+func exposeHostPorts(specs []string, existing []corev1.ContainerPort) ([]corev1.ContainerPort, error) {
+	seen := make(map[portKey]bool)
+	for _, p := range existing {
+		seen[portKey{p.ContainerPort, p.Protocol}] = true
+	}
+
+	ports := existing
+	for i, spec := range specs {
+		if strings.Contains(spec, ":") {
+			return nil, fmt.Errorf("invalid ExposeHostPort %q: expected port[-end][/protocol]", spec)
+		}
+		mappings, err := parsePortSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ExposeHostPort %q: %w", spec, err)
+		}
+		for n, m := range mappings {
+			if k := (portKey{m.ContainerPort, m.Protocol}); !seen[k] {
+				seen[k] = true
+				ports = append(ports, corev1.ContainerPort{
+					Name:          portName("expose", i, n, len(mappings)),
+					ContainerPort: m.ContainerPort,
+					Protocol:      m.Protocol,
+				})
+			}
+		}
+	}
+	return ports, nil
+}
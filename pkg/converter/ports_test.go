@@ -0,0 +1,146 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []portMapping
+	}{
+		{"80", []portMapping{{"", 80, 80, corev1.ProtocolTCP}}},
+		{"8080:80", []portMapping{{"", 8080, 80, corev1.ProtocolTCP}}},
+		{":80", []portMapping{{"", 80, 80, corev1.ProtocolTCP}}},
+		{"53:53/udp", []portMapping{{"", 53, 53, corev1.ProtocolUDP}}},
+		{"9000/sctp", []portMapping{{"", 9000, 9000, corev1.ProtocolSCTP}}},
+		{"127.0.0.1:8080:80", []portMapping{{"127.0.0.1", 8080, 80, corev1.ProtocolTCP}}},
+		{"10.0.0.1::80", []portMapping{{"10.0.0.1", 80, 80, corev1.ProtocolTCP}}},
+		{"[::1]:80:80", []portMapping{{"::1", 80, 80, corev1.ProtocolTCP}}},
+		{"[2001:db8::1]:443:8443/tcp", []portMapping{{"2001:db8::1", 443, 8443, corev1.ProtocolTCP}}},
+		{"8000-8002:9000-9002", []portMapping{
+			{"", 8000, 9000, corev1.ProtocolTCP},
+			{"", 8001, 9001, corev1.ProtocolTCP},
+			{"", 8002, 9002, corev1.ProtocolTCP},
+		}},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePortSpec(tt.spec)
+		if err != nil {
+			t.Errorf("parsePortSpec(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("parsePortSpec(%q) returned %d mappings, expected %d", tt.spec, len(got), len(tt.expected))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("parsePortSpec(%q)[%d] = %+v, expected %+v", tt.spec, i, got[i], tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestParsePortSpec_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"http",
+		"80/icmp",
+		"70000",
+		"0",
+		"::1:80:80",
+		"[::1:80:80",
+		"[127.0.0.1]:80:80",
+		"999.0.0.1:80:80",
+		"8000-8010:9000-9005",
+		"8010-8000",
+		"1-65535",
+		"10000-10256:10000-10256",
+	} {
+		if _, err := parsePortSpec(spec); err == nil {
+			t.Errorf("parsePortSpec(%q) expected error, got nil", spec)
+		}
+	}
+}
+
+func TestConvertContainer_PublishPortGrammar(t *testing.T) {
+	input := `
+[Container]
+Image=coredns
+PublishPort=53:53/udp
+PublishPort=53:53/tcp
+PublishPort=127.0.0.1:9153:9153
+PublishPort=8000-8001:8000-8001
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "dns", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	var deployment *appsv1.Deployment
+	var service *corev1.Service
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deployment = o
+		case *corev1.Service:
+			service = o
+		}
+	}
+
+	if deployment == nil || service == nil {
+		t.Fatal("Expected Deployment and Service")
+	}
+
+	// 53/udp, 53/tcp, 9153, 8000, 8001
+	if len(deployment.Spec.Template.Spec.Containers[0].Ports) != 5 {
+		t.Errorf("Expected 5 container ports, got %d", len(deployment.Spec.Template.Spec.Containers[0].Ports))
+	}
+
+	// Loopback-bound 9153 is not exposed.
+	if len(service.Spec.Ports) != 4 {
+		t.Fatalf("Expected 4 service ports, got %d", len(service.Spec.Ports))
+	}
+	for _, sp := range service.Spec.Ports {
+		if sp.Port == 9153 {
+			t.Error("Loopback-bound port 9153 should not be exposed by the Service")
+		}
+	}
+	if service.Spec.Ports[0].Protocol != corev1.ProtocolUDP {
+		t.Errorf("Expected first service port to be UDP, got %s", service.Spec.Ports[0].Protocol)
+	}
+	if service.Spec.Ports[2].Name != "port-3-0" || service.Spec.Ports[3].Name != "port-3-1" {
+		t.Errorf("Unexpected range port names: %s, %s", service.Spec.Ports[2].Name, service.Spec.Ports[3].Name)
+	}
+}
+
+func TestConvertContainer_InvalidPublishPort(t *testing.T) {
+	input := `
+[Container]
+Image=nginx
+PublishPort=80/icmp
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	_, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err == nil {
+		t.Fatal("Expected error for invalid PublishPort, got nil")
+	}
+	if !strings.Contains(err.Error(), "invalid PublishPort") {
+		t.Errorf("Expected invalid PublishPort error, got: %v", err)
+	}
+}
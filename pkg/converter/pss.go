@@ -0,0 +1,289 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodSecurityLevel is a Pod Security Standard the generated pod templates are
+// checked against.
+type PodSecurityLevel string
+
+const (
+	PodSecurityNone       PodSecurityLevel = ""
+	PodSecurityBaseline   PodSecurityLevel = "baseline"
+	PodSecurityRestricted PodSecurityLevel = "restricted"
+)
+
+// ParsePodSecurityLevel parses a Pod Security Standard name. "none" and ""
+// disable the checks.
+func ParsePodSecurityLevel(s string) (PodSecurityLevel, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return PodSecurityNone, nil
+	case string(PodSecurityBaseline):
+		return PodSecurityBaseline, nil
+	case string(PodSecurityRestricted):
+		return PodSecurityRestricted, nil
+	}
+	return "", fmt.Errorf("unknown Pod Security Standard %q (expected none, baseline or restricted)", s)
+}
+
+// baselineCapabilities may be added under the baseline standard.
+var baselineCapabilities = []string{
+	"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD",
+	"NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// podmanDefaultCapabilities are the capabilities Podman grants containers
+// unless dropped.
+var podmanDefaultCapabilities = []string{
+	"CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "NET_BIND_SERVICE",
+	"SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+}
+
+// baselineSELinuxTypes may be set as seLinuxOptions.type under the baseline
+// standard.
+var baselineSELinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+
+// safeSysctls are the namespaced sysctls allowed under the baseline standard
+// and by the kubelet without --allowed-unsafe-sysctls.
+var safeSysctls = []string{
+	"kernel.shm_rmid_forced",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.tcp_syncookies",
+	"net.ipv4.ping_group_range",
+	"net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.tcp_keepalive_time",
+	"net.ipv4.tcp_fin_timeout",
+	"net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+}
+
+// podSecurityViolation is a check failing for a pod template, with the
+// Quadlet key it originates from.
+type podSecurityViolation struct {
+	container string
+	message   string
+	key       string
+}
+
+// applyPodSecurityStandard hardens the pod template when opts.Harden is set
+// and reports the remaining violations of opts.PodSecurity. containers[i] is
+// the unit of spec.Containers[i].
+func applyPodSecurityStandard(unit string, spec *corev1.PodSpec, containers []*quadlet.ContainerUnit, opts Options) {
+	if opts.Harden {
+		hardenPodSpec(spec)
+	}
+	if opts.PodSecurity == PodSecurityNone {
+		return
+	}
+	for _, v := range checkPodSecurity(opts.PodSecurity, spec, containers) {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", sanitize(v.warning(unit, opts.PodSecurity)))
+	}
+}
+
+// warning formats the violation for the unit owning the pod template, e.g.
+// "pod web: container app: ..." or "container app: ..." when the violation
+// is in the unit's own container.
+func (v podSecurityViolation) warning(unit string, level PodSecurityLevel) string {
+	subject := unit
+	if v.container != "" && unit != "container "+v.container {
+		subject = unit + ": container " + v.container
+	}
+	return fmt.Sprintf("%s: %s violates the %s Pod Security Standard (from %s).", subject, v.message, level, v.key)
+}
+
+// hardenPodSpec adds the secure defaults of the restricted standard that the
+// units leave open: dropping all capabilities, RuntimeDefault seccomp and no
+// privilege escalation. Explicit settings are kept. Podman's default
+// capabilities lost by dropping ALL are reported per container.
+func hardenPodSpec(spec *corev1.PodSpec) {
+	podSeccomp := spec.SecurityContext != nil && spec.SecurityContext.SeccompProfile != nil
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		if c.SecurityContext == nil {
+			c.SecurityContext = &corev1.SecurityContext{}
+		}
+		sc := c.SecurityContext
+		privileged := sc.Privileged != nil && *sc.Privileged
+		if !privileged {
+			if sc.Capabilities == nil {
+				sc.Capabilities = &corev1.Capabilities{}
+			}
+			if !slices.Contains(sc.Capabilities.Drop, "ALL") {
+				if removed := removedDefaultCapabilities(sc.Capabilities); len(removed) > 0 {
+					// #nosec G705
+					fmt.Fprintf(os.Stderr, "Warning: %s: --harden drops all capabilities, removing Podman's defaults %s; add back those the image needs with AddCapability=.\n", sanitize(c.Name), strings.Join(removed, ", "))
+				}
+				sc.Capabilities.Drop = append([]corev1.Capability{"ALL"}, sc.Capabilities.Drop...)
+			}
+		}
+		if sc.SeccompProfile == nil && !podSeccomp {
+			sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+		}
+		if sc.AllowPrivilegeEscalation == nil && !privileged && !slices.Contains(sc.Capabilities.Add, "SYS_ADMIN") {
+			allow := false
+			sc.AllowPrivilegeEscalation = &allow
+		}
+	}
+}
+
+// removedDefaultCapabilities returns the Podman default capabilities a
+// container keeps until ALL is dropped: those neither dropped nor added.
+func removedDefaultCapabilities(caps *corev1.Capabilities) []string {
+	listed := func(list []corev1.Capability, capability string) bool {
+		return slices.ContainsFunc(list, func(c corev1.Capability) bool {
+			return strings.TrimPrefix(string(c), "CAP_") == capability
+		})
+	}
+	var removed []string
+	for _, capability := range podmanDefaultCapabilities {
+		if !listed(caps.Drop, capability) && !listed(caps.Add, capability) {
+			removed = append(removed, capability)
+		}
+	}
+	return removed
+}
+
+// checkPodSecurity evaluates the pod template against the given standard.
+func checkPodSecurity(level PodSecurityLevel, spec *corev1.PodSpec, containers []*quadlet.ContainerUnit) []podSecurityViolation {
+	var violations []podSecurityViolation
+	add := func(container, message, key string) {
+		violations = append(violations, podSecurityViolation{container: container, message: message, key: key})
+	}
+	restricted := level == PodSecurityRestricted
+
+	if spec.HostNetwork {
+		add("", "hostNetwork", "Network=host")
+	}
+	if spec.HostPID || spec.HostIPC {
+		add("", "host namespaces", "PodmanArgs")
+	}
+	volumeSources := make(map[string]string)
+	for _, c := range containers {
+		for _, v := range c.Container.Volume {
+			source, _, _ := splitVolumeSpec(v)
+			volumeSources[source] = v
+		}
+	}
+	for _, v := range spec.Volumes {
+		switch {
+		case v.HostPath != nil && v.HostPath.Type != nil && *v.HostPath.Type == corev1.HostPathCharDev:
+			add("", fmt.Sprintf("hostPath device %s", v.HostPath.Path), "AddDevice")
+		case v.HostPath != nil:
+			add("", fmt.Sprintf("hostPath volume %s", v.HostPath.Path), "Volume="+volumeKey(volumeSources, v.HostPath.Path))
+		case restricted && v.ConfigMap == nil && v.CSI == nil && v.DownwardAPI == nil && v.EmptyDir == nil &&
+			v.Ephemeral == nil && v.PersistentVolumeClaim == nil && v.Projected == nil && v.Secret == nil:
+			add("", fmt.Sprintf("volume %s", v.Name), "Volume")
+		}
+	}
+	var psc corev1.PodSecurityContext
+	if spec.SecurityContext != nil {
+		psc = *spec.SecurityContext
+	}
+	for _, s := range psc.Sysctls {
+		if !slices.Contains(safeSysctls, s.Name) {
+			add("", fmt.Sprintf("sysctl %s", s.Name), "Sysctl")
+		}
+	}
+
+	for i, c := range spec.Containers {
+		name := c.Name
+		var sc corev1.SecurityContext
+		if c.SecurityContext != nil {
+			sc = *c.SecurityContext
+		}
+		var caps corev1.Capabilities
+		if sc.Capabilities != nil {
+			caps = *sc.Capabilities
+		}
+
+		if sc.Privileged != nil && *sc.Privileged {
+			add(name, "privileged: true", "PodmanArgs=--privileged")
+		}
+		for _, capability := range caps.Add {
+			allowed := slices.Contains(baselineCapabilities, string(capability))
+			if restricted {
+				allowed = capability == "NET_BIND_SERVICE"
+			}
+			if !allowed {
+				add(name, fmt.Sprintf("capabilities.add %s", capability), "AddCapability="+string(capability))
+			}
+		}
+		for _, p := range c.Ports {
+			if p.HostPort != 0 {
+				add(name, fmt.Sprintf("hostPort %d", p.HostPort), "PublishPort")
+			}
+		}
+		if sc.AppArmorProfile != nil && sc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+			add(name, "Unconfined AppArmor profile", "AppArmor")
+		}
+		if se := sc.SELinuxOptions; se != nil {
+			switch {
+			case !slices.Contains(baselineSELinuxTypes, se.Type):
+				key := "SecurityLabelType"
+				if i < len(containers) && containers[i].Container.SecurityLabelDisable {
+					key = "SecurityLabelDisable"
+				}
+				add(name, fmt.Sprintf("seLinuxOptions.type %s", se.Type), key)
+			case se.User != "" || se.Role != "":
+				add(name, "seLinuxOptions.user/role", "SecurityLabelType")
+			}
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			add(name, "procMount: Unmasked", "Unmask")
+		}
+
+		seccomp := sc.SeccompProfile
+		if seccomp == nil {
+			seccomp = psc.SeccompProfile
+		}
+		if seccomp != nil && seccomp.Type == corev1.SeccompProfileTypeUnconfined {
+			add(name, "Unconfined seccomp profile", "SeccompProfile")
+		}
+		if !restricted {
+			continue
+		}
+
+		if seccomp == nil {
+			add(name, "missing seccompProfile", "SeccompProfile")
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			add(name, "allowPrivilegeEscalation not false", "NoNewPrivileges")
+		}
+		if !slices.Contains(caps.Drop, "ALL") {
+			add(name, "capabilities not dropping ALL", "DropCapability")
+		}
+		runAsNonRoot := sc.RunAsNonRoot
+		if runAsNonRoot == nil {
+			runAsNonRoot = psc.RunAsNonRoot
+		}
+		runAsUser := sc.RunAsUser
+		if runAsUser == nil {
+			runAsUser = psc.RunAsUser
+		}
+		switch {
+		case runAsUser != nil && *runAsUser == 0:
+			add(name, "runAsUser: 0", "User")
+		case runAsNonRoot == nil || !*runAsNonRoot:
+			add(name, "runAsNonRoot not true", "User")
+		}
+	}
+	return violations
+}
+
+// volumeKey returns the Volume= value mounting a host path, or the path itself.
+func volumeKey(sources map[string]string, path string) string {
+	if v, ok := sources[path]; ok {
+		return v
+	}
+	return path
+}
@@ -0,0 +1,136 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"slices"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCheckPodSecurity(t *testing.T) {
+	input := `
+[Container]
+Image=app
+Network=host
+AddCapability=SYS_ADMIN
+AddCapability=CHOWN
+Volume=/srv/data:/data
+SeccompProfile=unconfined
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := &objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	units := []*quadlet.ContainerUnit{qContainer}
+
+	keys := func(level PodSecurityLevel) []string {
+		var keys []string
+		for _, v := range checkPodSecurity(level, spec, units) {
+			keys = append(keys, v.key)
+		}
+		return keys
+	}
+
+	baseline := keys(PodSecurityBaseline)
+	for _, want := range []string{"Network=host", "AddCapability=SYS_ADMIN", "Volume=/srv/data:/data", "SeccompProfile"} {
+		if !slices.Contains(baseline, want) {
+			t.Errorf("Expected baseline violation from %s, got %v", want, baseline)
+		}
+	}
+	if slices.Contains(baseline, "AddCapability=CHOWN") {
+		t.Error("CHOWN is allowed under baseline")
+	}
+
+	restricted := keys(PodSecurityRestricted)
+	for _, want := range []string{"AddCapability=CHOWN", "NoNewPrivileges", "DropCapability", "User"} {
+		if !slices.Contains(restricted, want) {
+			t.Errorf("Expected restricted violation from %s, got %v", want, restricted)
+		}
+	}
+}
+
+func TestConvertContainer_Harden(t *testing.T) {
+	input := `
+[Container]
+Image=app
+User=1000
+AddCapability=NET_BIND_SERVICE
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, &Options{Harden: true})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := &objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	sc := spec.Containers[0].SecurityContext
+	if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") || !slices.Contains(sc.Capabilities.Add, "NET_BIND_SERVICE") {
+		t.Errorf("Expected drop ALL while keeping added capabilities, got %+v", sc.Capabilities)
+	}
+	if sc.SeccompProfile == nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("Expected RuntimeDefault seccomp, got %+v", sc.SeccompProfile)
+	}
+	if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+		t.Error("Expected allowPrivilegeEscalation: false")
+	}
+	if v := checkPodSecurity(PodSecurityRestricted, spec, []*quadlet.ContainerUnit{qContainer}); len(v) != 0 {
+		t.Errorf("Expected hardened spec to satisfy restricted, got %+v", v)
+	}
+}
+
+func TestConvertContainer_HardenKeepsExplicitSettings(t *testing.T) {
+	input := `
+[Container]
+Image=app
+SeccompProfile=unconfined
+PodmanArgs=--privileged
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, &Options{Harden: true})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	sc := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].SecurityContext
+	if sc.SeccompProfile.Type != corev1.SeccompProfileTypeUnconfined {
+		t.Errorf("Expected explicit Unconfined seccomp to be kept, got %s", sc.SeccompProfile.Type)
+	}
+	if sc.Capabilities != nil || sc.AllowPrivilegeEscalation != nil {
+		t.Errorf("Privileged containers must not be hardened, got %+v", sc)
+	}
+}
+
+func TestRemovedDefaultCapabilities(t *testing.T) {
+	caps := &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}, Drop: []corev1.Capability{"CAP_CHOWN", "KILL"}}
+	want := []string{"DAC_OVERRIDE", "FOWNER", "FSETID", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	if got := removedDefaultCapabilities(caps); !slices.Equal(got, want) {
+		t.Errorf("removedDefaultCapabilities() = %v, want %v", got, want)
+	}
+}
+
+func TestPodSecurityViolationWarning(t *testing.T) {
+	v := podSecurityViolation{container: "a", message: "runAsNonRoot not true", key: "User"}
+	tests := []struct {
+		unit string
+		want string
+	}{
+		{"container a", "container a: runAsNonRoot not true violates the restricted Pod Security Standard (from User)."},
+		{"container owner", "container owner: container a: runAsNonRoot not true violates the restricted Pod Security Standard (from User)."},
+		{"pod web", "pod web: container a: runAsNonRoot not true violates the restricted Pod Security Standard (from User)."},
+	}
+	for _, tt := range tests {
+		if got := v.warning(tt.unit, PodSecurityRestricted); got != tt.want {
+			t.Errorf("warning(%q) = %q, want %q", tt.unit, got, tt.want)
+		}
+	}
+}
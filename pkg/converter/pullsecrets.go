@@ -0,0 +1,204 @@
+package converter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// redactedCredential stands in for the user and password in --redact mode.
+const redactedCredential = "REDACTED"
+
+// dockerConfig is the containers-auth.json / .dockerconfigjson format.
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredHelpers map[string]string     `json:"credHelpers,omitempty"`
+}
+
+type dockerAuth struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// imageRegistry returns the registry host of an image reference. Short
+// names are Docker Hub images.
+func imageRegistry(image string) string {
+	if !isQualifiedImage(image) {
+		return "docker.io"
+	}
+	first, _, _ := strings.Cut(image, "/")
+	return first
+}
+
+// authRegistry normalizes an auth file key, which may be a URL or carry a
+// repository path, to the registry host it applies to.
+func authRegistry(key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host, _, _ := strings.Cut(key, "/")
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		return "docker.io"
+	}
+	return host
+}
+
+// pullSecretName is the Secret generated for an .image unit.
+func pullSecretName(unit string) string {
+	return unit + "-pull-secret"
+}
+
+// unitImage returns the image pulled by containers using an .image unit,
+// after qualification and registry rewrites.
+func unitImage(i *quadlet.ImageUnit, opts Options) string {
+	image := i.Image.Image
+	if i.Image.ImageTag != "" {
+		image = i.Image.ImageTag
+	}
+	return rewriteRegistry(qualifyImage(image, opts), opts)
+}
+
+// hasPullCredentials reports whether an .image unit names credentials.
+func hasPullCredentials(i *quadlet.ImageUnit) bool {
+	return i.Image.AuthFile != "" || i.Image.Creds != ""
+}
+
+// pullSecret builds the kubernetes.io/dockerconfigjson Secret of an .image
+// unit from its Creds= or AuthFile=, keeping only the entries for the
+// registry its image is pulled from. With opts.Redact the credentials are
+// replaced by a placeholder.
+func pullSecret(i *quadlet.ImageUnit, name string, opts Options) (*corev1.Secret, error) {
+	registry := imageRegistry(unitImage(i, opts))
+	config := dockerConfig{Auths: make(map[string]dockerAuth)}
+
+	switch {
+	case i.Image.Creds != "":
+		user, password, ok := strings.Cut(i.Image.Creds, ":")
+		if !ok {
+			return nil, fmt.Errorf("image %s: invalid Creds: expected user:password", name)
+		}
+		config.Auths[registry] = dockerAuth{
+			Username: user,
+			Password: password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(i.Image.Creds)),
+		}
+	default:
+		// #nosec G304
+		data, err := os.ReadFile(i.Image.AuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("image %s: failed to read AuthFile: %w", name, err)
+		}
+		var file dockerConfig
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("image %s: failed to parse AuthFile %s: %w", name, i.Image.AuthFile, err)
+		}
+		for key, auth := range file.Auths {
+			if authRegistry(key) == registry {
+				config.Auths[key] = auth
+			}
+		}
+		if _, ok := file.CredHelpers[registry]; ok {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: image %s: %s uses a credential helper, which cannot be exported; only stored credentials are used.\n", sanitize(name), sanitize(registry))
+		}
+		if len(config.Auths) == 0 {
+			return nil, fmt.Errorf("image %s: AuthFile %s has no credentials for %s", name, i.Image.AuthFile, registry)
+		}
+	}
+
+	if opts.Redact {
+		// A well-formed user:password pair that no registry accepts.
+		placeholder := redactedCredential + ":" + redactedCredential
+		for key := range config.Auths {
+			config.Auths[key] = dockerAuth{
+				Username: redactedCredential,
+				Password: redactedCredential,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(placeholder)),
+			}
+		}
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("image %s: %w", name, err)
+	}
+
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pullSecretName(name),
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: data},
+	}, nil
+}
+
+// BuildPullSecrets builds the pull Secrets of the .image units in
+// opts.Images, reading each AuthFile= once. Units whose Secret cannot be
+// built are reported and left out. Store the result in
+// opts.PullSecretsByRegistry before converting.
+func BuildPullSecrets(opts *Options) map[string][]*corev1.Secret {
+	o := opts.withDefaults()
+	return buildPullSecrets(o.Images, o)
+}
+
+func buildPullSecrets(images map[string]*quadlet.ImageUnit, opts Options) map[string][]*corev1.Secret {
+	secrets := make(map[string][]*corev1.Secret)
+	if !opts.PullSecrets {
+		return secrets
+	}
+	units := make([]string, 0, len(images))
+	for unit := range images {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	for _, unit := range units {
+		i := images[unit]
+		if !hasPullCredentials(i) {
+			continue
+		}
+		secret, err := pullSecret(i, unit, opts)
+		if err != nil {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s; no pull Secret is generated and pods are not given one.\n", sanitize(err.Error()))
+			continue
+		}
+		if opts.Redact {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: .image unit %s: Secret %s holds placeholder credentials; replace it before applying.\n", sanitize(unit), secret.Name)
+		}
+		registry := imageRegistry(unitImage(i, opts))
+		secrets[registry] = append(secrets[registry], secret)
+	}
+	return secrets
+}
+
+// applyPullSecrets references the pull Secrets of opts.PullSecretsByRegistry
+// for the registries serving the pod's images.
+func applyPullSecrets(spec *corev1.PodSpec, opts Options) {
+	if !opts.PullSecrets {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, c := range spec.Containers {
+		registry := imageRegistry(c.Image)
+		if seen[registry] {
+			continue
+		}
+		seen[registry] = true
+		for _, secret := range opts.PullSecretsByRegistry[registry] {
+			spec.ImagePullSecrets = append(spec.ImagePullSecrets, corev1.LocalObjectReference{Name: secret.Name})
+		}
+	}
+	sort.Slice(spec.ImagePullSecrets, func(a, b int) bool {
+		return spec.ImagePullSecrets[a].Name < spec.ImagePullSecrets[b].Name
+	})
+}
@@ -0,0 +1,184 @@
+package converter
+
+import (
+	"encoding/json"
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertImage_PullSecret(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), "auth.json")
+	auth := `{"auths": {
+		"quay.io": {"auth": "dXNlcjpwYXNz"},
+		"https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="}
+	}}`
+	if err := os.WriteFile(authFile, []byte(auth), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	iUnit, _ := parser.Parse(strings.NewReader("[Image]\nImage=quay.io/org/app:1.2\nAuthFile=" + authFile + "\n"))
+	qImage := quadlet.LoadImage(iUnit)
+
+	decode := func(opts *Options) dockerConfig {
+		t.Helper()
+		objs, err := ConvertImage(qImage, "app", opts)
+		if err != nil {
+			t.Fatalf("ConvertImage failed: %v", err)
+		}
+		secret := objs[0].(*corev1.Secret)
+		if secret.Name != "app-pull-secret" || secret.Type != corev1.SecretTypeDockerConfigJson {
+			t.Fatalf("Unexpected Secret: %s %s", secret.Name, secret.Type)
+		}
+		var config dockerConfig
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			t.Fatal(err)
+		}
+		return config
+	}
+
+	config := decode(&Options{PullSecrets: true})
+	if len(config.Auths) != 1 || config.Auths["quay.io"].Auth != "dXNlcjpwYXNz" {
+		t.Errorf("Expected only the quay.io credentials, got %+v", config.Auths)
+	}
+	// REDACTED:REDACTED, still a well-formed user:password pair.
+	if redacted := decode(&Options{PullSecrets: true, Redact: true}); redacted.Auths["quay.io"].Auth != "UkVEQUNURUQ6UkVEQUNURUQ=" {
+		t.Errorf("Expected redacted credentials, got %+v", redacted.Auths)
+	}
+
+	if objs, _ := ConvertImage(qImage, "app", nil); len(objs) != 0 {
+		t.Error("Pull Secrets must be opt-in")
+	}
+
+	opts := &Options{
+		PullSecrets: true,
+		Images:      map[string]*quadlet.ImageUnit{"app": qImage},
+	}
+	opts.PullSecretsByRegistry = BuildPullSecrets(opts)
+	cUnit, _ := parser.Parse(strings.NewReader("[Container]\nImage=app.image\n"))
+	objs, err := ConvertContainer(quadlet.LoadContainer(cUnit), "web", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	refs := objs[0].(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets
+	if len(refs) != 1 || refs[0].Name != "app-pull-secret" {
+		t.Errorf("Expected imagePullSecrets app-pull-secret, got %+v", refs)
+	}
+}
+
+func TestConvertImage_PullSecretCreds(t *testing.T) {
+	iUnit, _ := parser.Parse(strings.NewReader("[Image]\nImage=nginx\nCreds=bob:hunter2\n"))
+
+	objs, err := ConvertImage(quadlet.LoadImage(iUnit), "nginx", &Options{PullSecrets: true})
+	if err != nil {
+		t.Fatalf("ConvertImage failed: %v", err)
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(objs[0].(*corev1.Secret).Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		t.Fatal(err)
+	}
+	if a := config.Auths["docker.io"]; a.Username != "bob" || a.Password != "hunter2" || a.Auth != "Ym9iOmh1bnRlcjI=" {
+		t.Errorf("Unexpected docker.io credentials: %+v", config.Auths)
+	}
+}
+
+func TestConvertImage_PullSecretRewrite(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), "auth.json")
+	auth := `{"auths": {
+		"quay.io": {"auth": "dXNlcjpwYXNz"},
+		"mirror.internal": {"auth": "bWlycm9yOnNlY3JldA=="}
+	}}`
+	if err := os.WriteFile(authFile, []byte(auth), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	iUnit, _ := parser.Parse(strings.NewReader("[Image]\nImage=quay.io/org/app:1.2\nAuthFile=" + authFile + "\n"))
+	qImage := quadlet.LoadImage(iUnit)
+	opts := &Options{
+		PullSecrets:      true,
+		Images:           map[string]*quadlet.ImageUnit{"app": qImage},
+		RegistryRewrites: []RegistryRewrite{{From: "quay.io", To: "mirror.internal/quay"}},
+	}
+	opts.PullSecretsByRegistry = BuildPullSecrets(opts)
+
+	objs, err := ConvertImage(qImage, "app", opts)
+	if err != nil {
+		t.Fatalf("ConvertImage failed: %v", err)
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(objs[0].(*corev1.Secret).Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Auths) != 1 || config.Auths["mirror.internal"].Auth != "bWlycm9yOnNlY3JldA==" {
+		t.Errorf("Expected only the mirror credentials, got %+v", config.Auths)
+	}
+
+	cUnit, _ := parser.Parse(strings.NewReader("[Container]\nImage=app.image\n"))
+	objs, err = ConvertContainer(quadlet.LoadContainer(cUnit), "web", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if refs := objs[0].(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets; len(refs) != 1 || refs[0].Name != "app-pull-secret" {
+		t.Errorf("Expected imagePullSecrets app-pull-secret, got %+v", refs)
+	}
+}
+
+func TestConvertImage_PullSecretInvalidAuthFile(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(authFile, []byte(`{"auths": {"docker.io": {"auth": "aHViOnNlY3JldA=="}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{PullSecrets: true, Images: map[string]*quadlet.ImageUnit{}}
+	for name, file := range map[string]string{"missing": authFile + ".missing", "other": authFile} {
+		iUnit, _ := parser.Parse(strings.NewReader("[Image]\nImage=quay.io/org/" + name + "\nAuthFile=" + file + "\n"))
+		opts.Images[name] = quadlet.LoadImage(iUnit)
+
+		objs, err := ConvertImage(opts.Images[name], name, opts)
+		if err != nil || len(objs) != 0 {
+			t.Errorf("%s: expected no Secret and a warning, got %v (err %v)", name, objs, err)
+		}
+	}
+
+	opts.PullSecretsByRegistry = BuildPullSecrets(opts)
+	cUnit, _ := parser.Parse(strings.NewReader("[Container]\nImage=missing.image\n"))
+	objs, err := ConvertContainer(quadlet.LoadContainer(cUnit), "web", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if refs := objs[0].(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets; len(refs) != 0 {
+		t.Errorf("Expected no dangling imagePullSecrets, got %+v", refs)
+	}
+}
+
+func TestConvertImage_PullSecretsBuiltOnce(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(authFile, []byte(`{"auths": {"quay.io": {"auth": "dXNlcjpwYXNz"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	iUnit, _ := parser.Parse(strings.NewReader("[Image]\nImage=quay.io/org/app:1.2\nAuthFile=" + authFile + "\n"))
+	qImage := quadlet.LoadImage(iUnit)
+	opts := &Options{PullSecrets: true, Images: map[string]*quadlet.ImageUnit{"app": qImage}}
+	opts.PullSecretsByRegistry = BuildPullSecrets(opts)
+
+	// The AuthFile is not read again once the Secrets are built.
+	if err := os.Remove(authFile); err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := ConvertImage(qImage, "app", opts)
+	if err != nil || len(objs) != 1 || objs[0] != opts.PullSecretsByRegistry["quay.io"][0] {
+		t.Fatalf("Expected the prepared Secret, got %v (err %v)", objs, err)
+	}
+	cUnit, _ := parser.Parse(strings.NewReader("[Container]\nImage=app.image\n"))
+	objs, err = ConvertContainer(quadlet.LoadContainer(cUnit), "web", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if refs := objs[0].(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets; len(refs) != 1 || refs[0].Name != "app-pull-secret" {
+		t.Errorf("Expected imagePullSecrets app-pull-secret, got %+v", refs)
+	}
+}
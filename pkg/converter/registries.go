@@ -0,0 +1,189 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// RegistriesConf is the short-name configuration of containers-registries.conf(5)
+// and its shortnames.conf drop-ins.
+type RegistriesConf struct {
+	// Aliases maps short names to fully-qualified repositories.
+	Aliases map[string]string
+	// SearchRegistries are the unqualified-search-registries, in order.
+	SearchRegistries []string
+}
+
+// ParseRegistriesConf reads the [aliases] table and
+// unqualified-search-registries of a registries.conf or shortnames.conf file
+// into conf. Later files override earlier ones, as drop-ins do; other
+// settings are ignored.
+func ParseRegistriesConf(r io.Reader, conf *RegistriesConf) error {
+	scanner := bufio.NewScanner(r)
+	table := ""
+	for scanner.Scan() {
+		line := stripTOMLComment(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("malformed line %q", line)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+		value = strings.TrimSpace(value)
+		// Arrays may span several lines.
+		for strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") && scanner.Scan() {
+			value += " " + stripTOMLComment(scanner.Text())
+		}
+
+		switch {
+		case table == "aliases":
+			alias := tomlStrings(value)
+			if len(alias) != 1 {
+				return fmt.Errorf("invalid alias for %s: %s", key, value)
+			}
+			if conf.Aliases == nil {
+				conf.Aliases = make(map[string]string)
+			}
+			conf.Aliases[key] = alias[0]
+		case table == "" && key == "unqualified-search-registries":
+			conf.SearchRegistries = tomlStrings(value)
+		}
+	}
+	return scanner.Err()
+}
+
+// stripTOMLComment trims a line and drops a trailing comment outside quotes.
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return strings.TrimSpace(line[:i])
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// tomlStrings returns the quoted strings of a TOML string or string array.
+func tomlStrings(value string) []string {
+	var values []string
+	for value != "" {
+		start := strings.IndexAny(value, `"'`)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start+1:], value[start])
+		if end < 0 {
+			break
+		}
+		values = append(values, value[start+1:start+1+end])
+		value = value[start+end+2:]
+	}
+	return values
+}
+
+// RegistryRewrite replaces the registry, or repository prefix, From of
+// image references with To, e.g. to pull through an internal mirror.
+type RegistryRewrite struct {
+	From string
+	To   string
+}
+
+// ParseRegistryRewrite parses an --image-registry-rewrite entry, <from>=<to>.
+func ParseRegistryRewrite(s string) (RegistryRewrite, error) {
+	from, to, ok := strings.Cut(s, "=")
+	from, to = strings.TrimSuffix(from, "/"), strings.TrimSuffix(to, "/")
+	if !ok || from == "" || to == "" {
+		return RegistryRewrite{}, fmt.Errorf("invalid registry rewrite %q: expected <from>=<to>", s)
+	}
+	return RegistryRewrite{From: from, To: to}, nil
+}
+
+// isQualifiedImage reports whether an image reference names its registry:
+// a first component with a dot, a port or "localhost".
+func isQualifiedImage(image string) bool {
+	first, _, hasSlash := strings.Cut(image, "/")
+	return hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost")
+}
+
+// splitImageReference splits an image reference into its repository and its
+// :tag or @digest suffix.
+func splitImageReference(image string) (repo string, suffix string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i:]
+	}
+	return image, ""
+}
+
+// qualifyImage returns the fully-qualified form of a short image name, as
+// Podman resolves it: its alias, or the name on the first search registry,
+// docker.io by default. Single-component Docker Hub names live under
+// library/. Without opts.QualifyImages, images are returned as is.
+func qualifyImage(image string, opts Options) string {
+	if !opts.QualifyImages || image == "" || isQualifiedImage(image) {
+		return image
+	}
+	repo, suffix := splitImageReference(image)
+	if alias, ok := opts.Registries.Aliases[repo]; ok {
+		return alias + suffix
+	}
+	registry := "docker.io"
+	if len(opts.Registries.SearchRegistries) > 0 {
+		registry = opts.Registries.SearchRegistries[0]
+	}
+	if registry == "docker.io" && !strings.Contains(repo, "/") {
+		repo = "library/" + repo
+	}
+	return registry + "/" + repo + suffix
+}
+
+// rewriteRegistry applies the longest opts.RegistryRewrites prefix matching
+// a qualified image reference.
+func rewriteRegistry(image string, opts Options) string {
+	match := -1
+	for i, r := range opts.RegistryRewrites {
+		rest, ok := strings.CutPrefix(image, r.From)
+		if !ok || rest != "" && rest[0] != '/' && (!strings.Contains(r.From, "/") || rest[0] != ':' && rest[0] != '@') {
+			continue
+		}
+		if match < 0 || len(r.From) > len(opts.RegistryRewrites[match].From) {
+			match = i
+		}
+	}
+	if match < 0 {
+		return image
+	}
+	r := opts.RegistryRewrites[match]
+	return r.To + strings.TrimPrefix(image, r.From)
+}
+
+// normalizeImage fully qualifies a container's image and applies the
+// registry rewrites. A short name on a search list Podman would try in
+// order is reported.
+func normalizeImage(image string, name string, opts Options) string {
+	qualified := qualifyImage(image, opts)
+	repo, _ := splitImageReference(image)
+	if _, aliased := opts.Registries.Aliases[repo]; qualified != image && !aliased && len(opts.Registries.SearchRegistries) > 1 {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: short name %s resolved to %s; Podman would search %s in order.\n", sanitize(name), sanitize(image), sanitize(qualified), sanitize(strings.Join(opts.Registries.SearchRegistries, ", ")))
+	}
+	return rewriteRegistry(qualified, opts)
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"slices"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestParseRegistriesConf(t *testing.T) {
+	registries := `# registries.conf
+unqualified-search-registries = [
+  "registry.fedoraproject.org", # Fedora first
+  "docker.io",
+]
+short-name-mode = "enforcing"
+
+[[registry]]
+location = "quay.io"
+`
+	shortnames := `[aliases]
+  "fedora" = "registry.fedoraproject.org/fedora"
+  'nginx' = "docker.io/library/nginx" # official image
+`
+	var conf RegistriesConf
+	for _, content := range []string{registries, shortnames} {
+		if err := ParseRegistriesConf(strings.NewReader(content), &conf); err != nil {
+			t.Fatalf("ParseRegistriesConf failed: %v", err)
+		}
+	}
+	if !slices.Equal(conf.SearchRegistries, []string{"registry.fedoraproject.org", "docker.io"}) {
+		t.Errorf("Unexpected search registries: %v", conf.SearchRegistries)
+	}
+	if len(conf.Aliases) != 2 || conf.Aliases["fedora"] != "registry.fedoraproject.org/fedora" || conf.Aliases["nginx"] != "docker.io/library/nginx" {
+		t.Errorf("Unexpected aliases: %v", conf.Aliases)
+	}
+}
+
+func TestNormalizeImage(t *testing.T) {
+	opts := Options{
+		QualifyImages: true,
+		Registries:    RegistriesConf{Aliases: map[string]string{"fedora": "registry.fedoraproject.org/fedora"}},
+		RegistryRewrites: []RegistryRewrite{
+			{From: "docker.io", To: "mirror.example.com/dockerhub"},
+			{From: "docker.io/library/redis", To: "mirror.example.com/cache/redis"},
+		},
+	}
+	tests := map[string]string{
+		"fedora:40":                     "registry.fedoraproject.org/fedora:40",
+		"nginx":                         "mirror.example.com/dockerhub/library/nginx",
+		"bitnami/redis:7":               "mirror.example.com/dockerhub/bitnami/redis:7",
+		"redis@sha256:abc":              "mirror.example.com/cache/redis@sha256:abc",
+		"docker.io:5000/app":            "docker.io:5000/app",
+		"quay.io/org/app:1.0":           "quay.io/org/app:1.0",
+		"localhost/app":                 "localhost/app",
+		"docker.io/library/redis-tools": "mirror.example.com/dockerhub/library/redis-tools",
+	}
+	for image, want := range tests {
+		if got := normalizeImage(image, "app", opts); got != want {
+			t.Errorf("normalizeImage(%q) = %q, want %q", image, got, want)
+		}
+	}
+
+	if got := normalizeImage("nginx", "app", Options{}); got != "nginx" {
+		t.Errorf("Expected short names kept without QualifyImages, got %q", got)
+	}
+	opts.Registries.SearchRegistries = []string{"quay.io", "docker.io"}
+	opts.RegistryRewrites = nil
+	if got := normalizeImage("org/app", "app", opts); got != "quay.io/org/app" {
+		t.Errorf("Expected the first search registry, got %q", got)
+	}
+}
+
+func TestConvertContainer_RegistryRewrite(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=nginx:1.27\n"))
+	objs, err := ConvertContainer(quadlet.LoadContainer(unit), "web", nil, &Options{
+		RegistryRewrites: []RegistryRewrite{{From: "docker.io", To: "registry.internal"}},
+	})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	image := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image
+	if image != "registry.internal/library/nginx:1.27" {
+		t.Errorf("Expected the rewritten, qualified image, got %s", image)
+	}
+}
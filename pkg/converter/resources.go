@@ -0,0 +1,214 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// sizeUnits are the multipliers of Podman and systemd size suffixes, which
+// are binary regardless of case: 64m is 64 MiB.
+var sizeUnits = map[string]int64{
+	"":  1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+	"p": 1 << 50,
+}
+
+// parseSize parses a Podman size such as 512m, 1g or 1GiB into a binary
+// quantity.
+func parseSize(s string) (resource.Quantity, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	split := strings.IndexFunc(lower, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if split < 0 {
+		split = len(lower)
+	}
+	number := lower[:split]
+	unit := strings.TrimSuffix(strings.TrimSuffix(lower[split:], "b"), "i")
+	multiplier, ok := sizeUnits[unit]
+	value, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil || value < 0 {
+		return resource.Quantity{}, fmt.Errorf("invalid size %q", s)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits.
+	if value*float64(multiplier) >= math.MaxInt64 {
+		return resource.Quantity{}, fmt.Errorf("size %q is too large", s)
+	}
+	return *resource.NewQuantity(int64(value*float64(multiplier)), resource.BinarySI), nil
+}
+
+// containerResources computes the container's requests and limits from the
+// Podman flags and the unit's systemd resource controls. Where both set a
+// limit, the stricter one wins, as both cgroups apply. A limit without a
+// request gets opts.RequestRatio of it as request.
+func containerResources(c *quadlet.ContainerUnit, name string, opts Options) (corev1.ResourceRequirements, error) {
+	var res corev1.ResourceRequirements
+
+	cpuLimit, err := minQuantity(
+		quantitySource{"--cpus", c.Container.CPUs, parseCPUs},
+		quantitySource{"CPUQuota", c.Service.CPUQuota, parseCPUQuota},
+	)
+	if err != nil {
+		return res, err
+	}
+	cpuRequest, err := minQuantity(
+		quantitySource{"--cpu-shares", c.Container.CPUShares, parseCPUShares},
+		quantitySource{"CPUWeight", c.Service.CPUWeight, parseCPUWeight},
+	)
+	if err != nil {
+		return res, err
+	}
+	memLimit, err := minQuantity(
+		quantitySource{"Memory", c.Container.Memory, parseMemory},
+		quantitySource{"MemoryMax", c.Service.MemoryMax, parseMemory},
+	)
+	if err != nil {
+		return res, err
+	}
+	memRequest, err := minQuantity(
+		quantitySource{"--memory-reservation", c.Container.MemoryReservation, parseMemory},
+		quantitySource{"MemoryLow", c.Service.MemoryLow, parseMemory},
+	)
+	if err != nil {
+		return res, err
+	}
+
+	set := func(list *corev1.ResourceList, r corev1.ResourceName, q *resource.Quantity) {
+		if q == nil {
+			return
+		}
+		if *list == nil {
+			*list = corev1.ResourceList{}
+		}
+		(*list)[r] = *q
+	}
+	for _, r := range []struct {
+		name           corev1.ResourceName
+		limit, request *resource.Quantity
+	}{
+		{corev1.ResourceCPU, cpuLimit, cpuRequest},
+		{corev1.ResourceMemory, memLimit, memRequest},
+	} {
+		request := r.request
+		switch {
+		case r.limit == nil:
+		case request == nil:
+			request = scaleQuantity(*r.limit, opts.RequestRatio)
+		case request.Cmp(*r.limit) > 0:
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s request %s exceeds its limit %s; using the limit.\n", sanitize(name), r.name, request.String(), r.limit.String())
+			request = r.limit
+		}
+		set(&res.Limits, r.name, r.limit)
+		set(&res.Requests, r.name, request)
+	}
+	return res, nil
+}
+
+// quantitySource is a resource setting and the parser for its syntax.
+type quantitySource struct {
+	key   string
+	value string
+	parse func(string) (*resource.Quantity, error)
+}
+
+// minQuantity returns the smallest of the set sources, or nil if none is set.
+func minQuantity(sources ...quantitySource) (*resource.Quantity, error) {
+	var result *resource.Quantity
+	for _, s := range sources {
+		if s.value == "" {
+			continue
+		}
+		q, err := s.parse(s.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", s.key, err)
+		}
+		if q != nil && (result == nil || q.Cmp(*result) < 0) {
+			result = q
+		}
+	}
+	return result, nil
+}
+
+// scaleQuantity returns ratio times q, keeping q's format.
+func scaleQuantity(q resource.Quantity, ratio float64) *resource.Quantity {
+	if ratio == 1 {
+		return &q
+	}
+	if q.Format == resource.DecimalSI {
+		return resource.NewMilliQuantity(int64(math.Round(float64(q.MilliValue())*ratio)), resource.DecimalSI)
+	}
+	return resource.NewQuantity(int64(math.Round(float64(q.Value())*ratio)), q.Format)
+}
+
+// parseMemory accepts Podman and systemd sizes as well as Kubernetes
+// quantities. systemd's "infinity" means no limit.
+func parseMemory(s string) (*resource.Quantity, error) {
+	if s == "infinity" {
+		return nil, nil
+	}
+	if strings.HasSuffix(s, "%") {
+		return nil, fmt.Errorf("percentages of host memory are not supported: %q", s)
+	}
+	q, err := parseSize(s)
+	if err != nil {
+		var qErr error
+		if q, qErr = resource.ParseQuantity(s); qErr != nil {
+			return nil, err
+		}
+	}
+	return &q, nil
+}
+
+// parseCPUs parses --cpus, a number of CPUs.
+func parseCPUs(s string) (*resource.Quantity, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return nil, fmt.Errorf("invalid CPU count %q", s)
+	}
+	return cpuQuantity(v), nil
+}
+
+// parseCPUQuota parses systemd's CPUQuota=, a percentage of one CPU.
+func parseCPUQuota(s string) (*resource.Quantity, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil || !strings.HasSuffix(s, "%") || v <= 0 {
+		return nil, fmt.Errorf("invalid CPU quota %q: expected a percentage", s)
+	}
+	return cpuQuantity(v / 100), nil
+}
+
+// parseCPUShares parses --cpu-shares. 1024 shares correspond to one CPU, the
+// inverse of the kubelet's request-to-shares conversion.
+func parseCPUShares(s string) (*resource.Quantity, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 2 || v > 262144 {
+		return nil, fmt.Errorf("invalid CPU shares %q", s)
+	}
+	return cpuQuantity(float64(v) / 1024), nil
+}
+
+// parseCPUWeight parses systemd's CPUWeight=. The default weight of 100
+// corresponds to 1024 shares, i.e. one CPU.
+func parseCPUWeight(s string) (*resource.Quantity, error) {
+	if s == "idle" {
+		return cpuQuantity(0.001), nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 1 || v > 10000 {
+		return nil, fmt.Errorf("invalid CPU weight %q", s)
+	}
+	return cpuQuantity(float64(v) / 100), nil
+}
+
+func cpuQuantity(cpus float64) *resource.Quantity {
+	return resource.NewMilliQuantity(max(int64(math.Round(cpus*1000)), 1), resource.DecimalSI)
+}
@@ -0,0 +1,88 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func convertResources(t *testing.T, input string, opts *Options) corev1.ResourceRequirements {
+	t.Helper()
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	return objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Resources
+}
+
+func TestConvertContainer_Resources(t *testing.T) {
+	res := convertResources(t, `
+[Container]
+Image=app
+Memory=1g
+PodmanArgs=--cpus=1.5 --cpu-shares=512 --memory-reservation=256m
+
+[Service]
+CPUQuota=200%
+MemoryMax=768M
+`, nil)
+
+	for name, c := range map[string]struct {
+		got  corev1.ResourceList
+		r    corev1.ResourceName
+		want string
+	}{
+		"cpu limit":      {res.Limits, corev1.ResourceCPU, "1500m"},
+		"cpu request":    {res.Requests, corev1.ResourceCPU, "500m"},
+		"memory limit":   {res.Limits, corev1.ResourceMemory, "768Mi"},
+		"memory request": {res.Requests, corev1.ResourceMemory, "256Mi"},
+	} {
+		q := c.got[c.r]
+		if q.String() != c.want {
+			t.Errorf("%s: expected %s, got %s", name, c.want, q.String())
+		}
+	}
+}
+
+func TestConvertContainer_ResourcesRequestRatio(t *testing.T) {
+	res := convertResources(t, `
+[Container]
+Image=app
+Memory=512m
+
+[Service]
+CPUQuota=100%
+`, &Options{RequestRatio: 0.5})
+
+	cpu, mem := res.Requests[corev1.ResourceCPU], res.Requests[corev1.ResourceMemory]
+	if cpu.String() != "500m" || mem.String() != "256Mi" {
+		t.Errorf("Expected requests at half the limits, got cpu %s memory %s", cpu.String(), mem.String())
+	}
+}
+
+func TestConvertContainer_ResourcesInvalid(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader(`
+[Container]
+Image=app
+
+[Service]
+CPUQuota=2
+`))
+	if _, err := ConvertContainer(quadlet.LoadContainer(unit), "app", nil, nil); err == nil {
+		t.Error("Expected an error for a CPUQuota without %")
+	}
+}
+
+func TestConvertContainer_ResourcesOverflow(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=app\nPodmanArgs=--memory 9000000p\n"))
+	if _, err := ConvertContainer(quadlet.LoadContainer(unit), "app", nil, nil); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Expected an error for a memory size overflowing int64, got %v", err)
+	}
+}
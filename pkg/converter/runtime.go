@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyRuntimeOptions maps ShmSize and Timezone onto the container and
+// reports PidsLimit, Ulimit, --security-opt values and other PodmanArgs that
+// have no equivalent. It returns the volumes the container needs.
+func applyRuntimeOptions(c *quadlet.ContainerUnit, name string, container *corev1.Container) ([]corev1.Volume, error) {
+	var volumes []corev1.Volume
+	cs := &c.Container
+
+	if cs.ShmSize != "" {
+		size, err := parseSize(cs.ShmSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ShmSize: %w", err)
+		}
+		// A memory-backed emptyDir over /dev/shm replaces the runtime's 64 MiB default.
+		volumes = append(volumes, corev1.Volume{
+			Name: "dshm",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: &size},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "dshm", MountPath: "/dev/shm"})
+	}
+
+	switch cs.Timezone {
+	case "":
+	case "local":
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: Timezone=local depends on the node; ignoring.\n", sanitize(name))
+	default:
+		container.Env = append(container.Env, corev1.EnvVar{Name: "TZ", Value: cs.Timezone})
+	}
+
+	if cs.PidsLimit != "" {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: PidsLimit=%s has no per-container equivalent; the kubelet's podPidsLimit applies.\n", sanitize(name), sanitize(cs.PidsLimit))
+	}
+	for _, u := range cs.Ulimit {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: Ulimit=%s has no Kubernetes equivalent; the container runtime's defaults on the node apply.\n", sanitize(name), sanitize(u))
+	}
+	var unknown []string
+	for i := 0; i < len(cs.PodmanArgs); i++ {
+		a := cs.PodmanArgs[i]
+		opt, ok := strings.CutPrefix(a, "--security-opt=")
+		if a == "--security-opt" && i+1 < len(cs.PodmanArgs) {
+			i++
+			opt, ok = cs.PodmanArgs[i], true
+		}
+		if ok {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: --security-opt %s is not mapped.\n", sanitize(name), sanitize(opt))
+			continue
+		}
+		unknown = append(unknown, a)
+	}
+	if len(unknown) > 0 {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: unrecognized PodmanArgs %s; ignoring.\n", sanitize(name), sanitize(strings.Join(unknown, " ")))
+	}
+	return volumes, nil
+}
+
+// applyRunInit shares the process namespace when a container sets RunInit,
+// making the pod's pause process PID 1 and reaping zombies like Podman's
+// catatonit.
+func applyRunInit(unit string, spec *corev1.PodSpec, containers []*quadlet.ContainerUnit) {
+	for _, c := range containers {
+		if c.Container.RunInit {
+			share := true
+			spec.ShareProcessNamespace = &share
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: RunInit mapped to shareProcessNamespace; containers of the pod can see each other's processes.\n", sanitize(unit))
+			return
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"slices"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestLoadContainer_PodmanArgs(t *testing.T) {
+	input := `
+[Container]
+Image=app
+ShmSize=64m
+PodmanArgs=--cpus 1.5 --cpu-shares=512 --memory-reservation 256m --shm-size=1g
+PodmanArgs=--device /dev/fuse --ulimit nofile=1024:2048 --sysctl net.core.somaxconn=1024
+PodmanArgs=--init --tz=Europe/Berlin --add-host db:10.0.0.5 --pids-limit -1
+PodmanArgs=--privileged --security-opt label=type:spc_t --rm --log-opt max-size=10m
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	c := quadlet.LoadContainer(unit).Container
+
+	if c.CPUs != "1.5" || c.CPUShares != "512" || c.MemoryReservation != "256m" {
+		t.Errorf("Unexpected CPU/memory settings: %q %q %q", c.CPUs, c.CPUShares, c.MemoryReservation)
+	}
+	if c.ShmSize != "1g" {
+		t.Errorf("Expected PodmanArgs to override ShmSize, got %q", c.ShmSize)
+	}
+	if !slices.Equal(c.AddDevice, []string{"/dev/fuse"}) || !slices.Equal(c.Ulimit, []string{"nofile=1024:2048"}) {
+		t.Errorf("Unexpected devices/ulimits: %v %v", c.AddDevice, c.Ulimit)
+	}
+	if c.Sysctl["net.core.somaxconn"] != "1024" {
+		t.Errorf("Unexpected sysctls: %v", c.Sysctl)
+	}
+	if !c.RunInit || c.Timezone != "Europe/Berlin" || c.PidsLimit != "-1" {
+		t.Errorf("Unexpected init/tz/pids: %v %q %q", c.RunInit, c.Timezone, c.PidsLimit)
+	}
+	if !slices.Equal(c.AddHost, []string{"db:10.0.0.5"}) {
+		t.Errorf("Unexpected hosts: %v", c.AddHost)
+	}
+	if !c.Privileged || c.SecurityLabelType != "spc_t" {
+		t.Errorf("Unexpected security settings: %v %q", c.Privileged, c.SecurityLabelType)
+	}
+	if want := []string{"--rm", "--log-opt", "max-size=10m"}; !slices.Equal(c.PodmanArgs, want) {
+		t.Errorf("Expected remaining PodmanArgs %v, got %v", want, c.PodmanArgs)
+	}
+}
+
+func TestLoadContainer_PodmanArgsValueFlags(t *testing.T) {
+	input := `
+[Container]
+Image=app
+PodmanArgs=--oom-score-adj -500 --no-hosts --cpus 2 --replace --security-opt label=user:system_u -e A=1
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	c := quadlet.LoadContainer(unit).Container
+
+	if c.CPUs != "2" {
+		t.Errorf("Expected --cpus 2 after boolean flags, got %q", c.CPUs)
+	}
+	want := []string{"--oom-score-adj", "-500", "--no-hosts", "--replace", "--security-opt", "label=user:system_u", "-e", "A=1"}
+	if !slices.Equal(c.PodmanArgs, want) {
+		t.Errorf("Expected remaining PodmanArgs %v, got %v", want, c.PodmanArgs)
+	}
+}
+
+func TestConvertContainer_RuntimeOptions(t *testing.T) {
+	input := `
+[Container]
+Image=app
+ShmSize=256m
+Timezone=Asia/Seoul
+RunInit=true
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+
+	if len(spec.Volumes) != 1 || spec.Volumes[0].EmptyDir == nil || spec.Volumes[0].EmptyDir.Medium != corev1.StorageMediumMemory {
+		t.Fatalf("Expected a memory-backed emptyDir for ShmSize, got %+v", spec.Volumes)
+	}
+	if got := spec.Volumes[0].EmptyDir.SizeLimit.Value(); got != 256<<20 {
+		t.Errorf("Expected sizeLimit 256Mi, got %d", got)
+	}
+	if m := spec.Containers[0].VolumeMounts; len(m) != 1 || m[0].MountPath != "/dev/shm" {
+		t.Errorf("Expected /dev/shm mount, got %+v", m)
+	}
+	if env := spec.Containers[0].Env; len(env) != 1 || env[0].Name != "TZ" || env[0].Value != "Asia/Seoul" {
+		t.Errorf("Expected TZ env, got %+v", env)
+	}
+	if spec.ShareProcessNamespace == nil || !*spec.ShareProcessNamespace {
+		t.Error("Expected shareProcessNamespace for RunInit")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"1024": 1024,
+		"64m":  64 << 20,
+		"1G":   1 << 30,
+		"2GiB": 2 << 30,
+		"1.5k": 1536,
+		"10mb": 10 << 20,
+	}
+	for in, want := range cases {
+		q, err := parseSize(in)
+		if err != nil {
+			t.Errorf("parseSize(%q) failed: %v", in, err)
+			continue
+		}
+		if q.Value() != want {
+			t.Errorf("parseSize(%q) = %d, want %d", in, q.Value(), want)
+		}
+	}
+	for _, in := range []string{"", "m", "12x", "-1g", "9000000p", "8192p", "9223372036854775807"} {
+		if _, err := parseSize(in); err == nil {
+			t.Errorf("parseSize(%q) should fail", in)
+		}
+	}
+}
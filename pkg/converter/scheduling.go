@@ -0,0 +1,74 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// platformAliases maps the architecture names Podman also accepts to the
+// GOARCH names nodes are labeled with.
+var platformAliases = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+	"armhf":   "arm",
+	"i386":    "386",
+}
+
+// imagePlatform returns the node labels required by the .image unit a
+// container references through Arch= and OS=.
+func imagePlatform(c *quadlet.ContainerUnit, name string, opts Options) map[string]string {
+	unit, ok := strings.CutSuffix(c.Container.Image, ".image")
+	if !ok {
+		return nil
+	}
+	i, ok := opts.Images[unit]
+	if !ok {
+		return nil
+	}
+	labels := make(map[string]string)
+	if arch := strings.ToLower(i.Image.Arch); arch != "" {
+		if alias, ok := platformAliases[arch]; ok {
+			arch = alias
+		}
+		labels[corev1.LabelArchStable] = arch
+	}
+	if osName := strings.ToLower(i.Image.OS); osName != "" {
+		labels[corev1.LabelOSStable] = osName
+	}
+	if i.Image.Variant != "" {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: Variant=%s of %s has no well-known node label; nodes are only selected by architecture.\n", sanitize(name), sanitize(i.Image.Variant), sanitize(c.Container.Image))
+	}
+	return labels
+}
+
+// applyImagePlatform pins the pod to nodes matching the Arch= and OS= of the
+// .image units its containers use, through spec.nodeSelector. All containers
+// share a node, so different requirements are an error. containers[i] is the
+// unit of spec.Containers[i].
+func applyImagePlatform(unit string, spec *corev1.PodSpec, containers []*quadlet.ContainerUnit, opts Options) error {
+	owners := make(map[string]string)
+	for i, c := range containers {
+		cName := spec.Containers[i].Name
+		labels := imagePlatform(c, cName, opts)
+		for _, k := range sortedKeys(labels) {
+			v := labels[k]
+			if prev, ok := spec.NodeSelector[k]; ok {
+				if prev != v {
+					return fmt.Errorf("%s: conflicting %s: %s needs %s, %s needs %s", unit, k, owners[k], prev, cName, v)
+				}
+				continue
+			}
+			if spec.NodeSelector == nil {
+				spec.NodeSelector = make(map[string]string)
+			}
+			spec.NodeSelector[k] = v
+			owners[k] = cName
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func loadImages(t *testing.T, units map[string]string) map[string]*quadlet.ImageUnit {
+	t.Helper()
+	images := make(map[string]*quadlet.ImageUnit)
+	for name, content := range units {
+		unit, err := parser.Parse(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		images[name] = quadlet.LoadImage(unit)
+	}
+	return images
+}
+
+func TestConvertPod_ImagePlatform(t *testing.T) {
+	opts := &Options{Images: loadImages(t, map[string]string{
+		"app":   "[Image]\nImage=quay.io/org/app\nArch=aarch64\nOS=linux\nVariant=v8\n",
+		"proxy": "[Image]\nImage=quay.io/org/proxy\nArch=arm64\n",
+	})}
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=app.image\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=proxy.image\n"))
+	c3, _ := parser.Parse(strings.NewReader("[Container]\nImage=busybox\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2), quadlet.LoadContainer(c3)}
+
+	objs, err := ConvertPod(quadlet.LoadPod(pUnit), containers, []string{"app", "proxy", "sidecar"}, "web", nil, opts)
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	selector := objs[0].(*appsv1.Deployment).Spec.Template.Spec.NodeSelector
+	if len(selector) != 2 || selector["kubernetes.io/arch"] != "arm64" || selector["kubernetes.io/os"] != "linux" {
+		t.Errorf("Expected arm64/linux nodeSelector, got %v", selector)
+	}
+}
+
+func TestConvertPod_ImagePlatformConflict(t *testing.T) {
+	opts := &Options{Images: loadImages(t, map[string]string{
+		"app":   "[Image]\nImage=quay.io/org/app\nArch=arm64\n",
+		"proxy": "[Image]\nImage=quay.io/org/proxy\nArch=amd64\n",
+	})}
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=app.image\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=proxy.image\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2)}
+
+	_, err := ConvertPod(quadlet.LoadPod(pUnit), containers, []string{"app", "proxy"}, "web", nil, opts)
+	if err == nil || !strings.Contains(err.Error(), "conflicting kubernetes.io/arch") {
+		t.Errorf("Expected architecture conflict error, got %v", err)
+	}
+}
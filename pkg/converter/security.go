@@ -0,0 +1,269 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PasswdEntry is the numeric identity of a user in a passwd file.
+type PasswdEntry struct {
+	UID int64
+	GID int64
+}
+
+// ParsePasswd reads a passwd(5) file into entries by user name.
+func ParsePasswd(r io.Reader) (map[string]PasswdEntry, error) {
+	entries := make(map[string]PasswdEntry)
+	err := scanIDFile(r, 4, func(fields []string) error {
+		uid, errUID := strconv.ParseInt(fields[2], 10, 64)
+		gid, errGID := strconv.ParseInt(fields[3], 10, 64)
+		if errUID != nil || errGID != nil {
+			return fmt.Errorf("invalid passwd entry for %s", fields[0])
+		}
+		entries[fields[0]] = PasswdEntry{UID: uid, GID: gid}
+		return nil
+	})
+	return entries, err
+}
+
+// ParseGroup reads a group(5) file into GIDs by group name.
+func ParseGroup(r io.Reader) (map[string]int64, error) {
+	groups := make(map[string]int64)
+	err := scanIDFile(r, 3, func(fields []string) error {
+		gid, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid group entry for %s", fields[0])
+		}
+		groups[fields[0]] = gid
+		return nil
+	})
+	return groups, err
+}
+
+func scanIDFile(r io.Reader, minFields int, entry func([]string) error) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < minFields {
+			return fmt.Errorf("malformed line %q", line)
+		}
+		if err := entry(fields); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// resolveUser returns the UID and GID a container runs as from User=, in the
+// forms uid, uid:gid, name and name:group, and Group=. Names are looked up
+// in opts.Passwd and opts.Groups; a user name without a group implies the
+// user's primary group. Unresolvable parts are reported and left unset.
+func resolveUser(c *quadlet.ContainerUnit, name string, opts Options) (uid *int64, gid *int64) {
+	user, group, _ := strings.Cut(c.Container.User, ":")
+	if c.Container.Group != "" {
+		group = c.Container.Group
+	}
+
+	if user != "" {
+		if id, err := strconv.ParseInt(user, 10, 64); err == nil {
+			uid = &id
+		} else if entry, ok := opts.Passwd[user]; ok {
+			uid = &entry.UID
+			if group == "" {
+				gid = &entry.GID
+			}
+		} else {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: cannot resolve user %s%s; runAsUser is not set.\n", sanitize(name), sanitize(user), idFileHint(opts.Passwd == nil, "--passwd-file"))
+		}
+	}
+
+	if group != "" {
+		if id, ok := lookupGroup(group, opts); ok {
+			gid = &id
+		} else {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: cannot resolve group %s%s; runAsGroup is not set.\n", sanitize(name), sanitize(group), idFileHint(opts.Groups == nil, "--group-file"))
+		}
+	}
+	return uid, gid
+}
+
+func lookupGroup(group string, opts Options) (int64, bool) {
+	if id, err := strconv.ParseInt(group, 10, 64); err == nil {
+		return id, true
+	}
+	id, ok := opts.Groups[group]
+	return id, ok
+}
+
+func idFileHint(missing bool, flag string) string {
+	if missing {
+		return " without " + flag
+	}
+	return ""
+}
+
+// applyPodSecurity sets the pod-level security context: GroupAdd becomes
+// supplementalGroups, and volumes mounted with :U, which Podman chowns to the
+// container user, set fsGroup to that user's group. A :z volume selects
+// recursive SELinux relabeling. containers[i] is the unit of
+// spec.Containers[i].
+func applyPodSecurity(unit string, spec *corev1.PodSpec, containers []*quadlet.ContainerUnit, opts Options) {
+	psc := &corev1.PodSecurityContext{}
+	for i, c := range containers {
+		cName := spec.Containers[i].Name
+
+		for _, g := range c.Container.GroupAdd {
+			if g == "keep-groups" {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: GroupAdd=keep-groups has no Kubernetes equivalent; ignoring.\n", sanitize(cName))
+				continue
+			}
+			gid, ok := lookupGroup(g, opts)
+			if !ok {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: cannot resolve GroupAdd=%s%s; ignoring.\n", sanitize(cName), sanitize(g), idFileHint(opts.Groups == nil, "--group-file"))
+				continue
+			}
+			if !slices.Contains(psc.SupplementalGroups, gid) {
+				psc.SupplementalGroups = append(psc.SupplementalGroups, gid)
+			}
+		}
+
+		chown := false
+		for _, v := range c.Container.Volume {
+			source, _, options := splitVolumeSpec(v)
+			chown = chown || slices.Contains(options, "U")
+			relabel := ""
+			switch {
+			case slices.Contains(options, "z"):
+				relabel = "z"
+				// Shared content: relabel recursively instead of per-pod mount
+				// options, which would make the volume private to one pod.
+				policy := corev1.SELinuxChangePolicyRecursive
+				psc.SELinuxChangePolicy = &policy
+			case slices.Contains(options, "Z"):
+				relabel = "Z"
+			}
+			if relabel != "" && strings.HasPrefix(source, "/") {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: hostPath %s is not relabeled by Kubernetes; :%s is ignored, label it on the node instead.\n", sanitize(cName), sanitize(source), relabel)
+			}
+		}
+		if !chown {
+			continue
+		}
+		var fsGroup *int64
+		if sc := spec.Containers[i].SecurityContext; sc != nil {
+			fsGroup = sc.RunAsGroup
+			if fsGroup == nil {
+				fsGroup = sc.RunAsUser
+			}
+		}
+		switch {
+		case fsGroup == nil:
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Volume with :U but no resolvable User=; fsGroup is not set.\n", sanitize(cName))
+		case psc.FSGroup == nil:
+			psc.FSGroup = fsGroup
+		case *psc.FSGroup != *fsGroup:
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: Volume with :U needs fsGroup %d, but %s already uses %d; keeping %d.\n", sanitize(cName), *fsGroup, sanitize(unit), *psc.FSGroup, *psc.FSGroup)
+		}
+	}
+
+	if len(psc.SupplementalGroups) > 0 || psc.FSGroup != nil || psc.SELinuxChangePolicy != nil {
+		spec.SecurityContext = psc
+	}
+}
+
+// podmanDefaultSeccomp is the profile Podman applies when none is given.
+const podmanDefaultSeccomp = "/usr/share/containers/seccomp.json"
+
+// applySecurityOptions maps SELinux labels, seccomp and AppArmor profiles,
+// Mask/Unmask and privileged mode onto a container security context. It
+// reports whether anything was set.
+func applySecurityOptions(c *quadlet.ContainerUnit, name string, sc *corev1.SecurityContext) bool {
+	cs := &c.Container
+	safeName := sanitize(name)
+
+	set := false
+	switch {
+	case cs.SecurityLabelDisable:
+		// Podman runs unlabeled containers as spc_t.
+		sc.SELinuxOptions = &corev1.SELinuxOptions{Type: "spc_t"}
+		set = true
+	case cs.SecurityLabelType != "" || cs.SecurityLabelLevel != "":
+		sc.SELinuxOptions = &corev1.SELinuxOptions{Type: cs.SecurityLabelType, Level: cs.SecurityLabelLevel}
+		set = true
+	}
+	if cs.SecurityLabelFileType != "" {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: SecurityLabelFileType=%s has no Kubernetes equivalent; volumes are labeled from the process label.\n", safeName, sanitize(cs.SecurityLabelFileType))
+	}
+
+	switch cs.SeccompProfile {
+	case "":
+	case "unconfined":
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+		set = true
+	case podmanDefaultSeccomp:
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+		set = true
+	default:
+		// Localhost profiles are relative to the kubelet's seccomp directory.
+		profile := path.Base(cs.SeccompProfile)
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &profile}
+		set = true
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: seccomp profile %s must be installed as %s in the kubelet's seccomp directory on every node.\n", safeName, sanitize(cs.SeccompProfile), sanitize(profile))
+	}
+
+	switch {
+	case cs.AppArmor == "":
+	case cs.AppArmor == "unconfined":
+		sc.AppArmorProfile = &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeUnconfined}
+		set = true
+	case strings.HasPrefix(cs.AppArmor, "containers-default"):
+		sc.AppArmorProfile = &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeRuntimeDefault}
+		set = true
+	default:
+		profile := cs.AppArmor
+		sc.AppArmorProfile = &corev1.AppArmorProfile{Type: corev1.AppArmorProfileTypeLocalhost, LocalhostProfile: &profile}
+		set = true
+	}
+
+	if len(cs.Unmask) > 0 {
+		// Kubernetes can only unmask /proc as a whole.
+		procMount := corev1.UnmaskedProcMount
+		sc.ProcMount = &procMount
+		set = true
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: Unmask=%s mapped to procMount: Unmasked, which unmasks all of /proc and requires hostUsers: false.\n", safeName, sanitize(strings.Join(cs.Unmask, ":")))
+	}
+	if len(cs.Mask) > 0 {
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: Mask=%s has no Kubernetes equivalent; ignoring.\n", safeName, sanitize(strings.Join(cs.Mask, ":")))
+	}
+
+	if cs.Privileged {
+		p := true
+		sc.Privileged = &p
+		set = true
+	}
+	return set
+}
@@ -0,0 +1,170 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertContainer_UserGroupNames(t *testing.T) {
+	passwd, err := ParsePasswd(strings.NewReader("root:x:0:0:root:/root:/bin/sh\n# comment\npostgres:x:70:70::/var/lib/postgresql:/bin/sh\n"))
+	if err != nil {
+		t.Fatalf("ParsePasswd failed: %v", err)
+	}
+	groups, err := ParseGroup(strings.NewReader("postgres:x:70:\nbackup:x:34:\n"))
+	if err != nil {
+		t.Fatalf("ParseGroup failed: %v", err)
+	}
+
+	input := `
+[Container]
+Image=postgres
+User=postgres
+GroupAdd=backup
+GroupAdd=2000
+Volume=pgdata.volume:/var/lib/postgresql/data:U
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "db", nil, &Options{Passwd: passwd, Groups: groups})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+
+	sc := spec.Containers[0].SecurityContext
+	if sc == nil || sc.RunAsUser == nil || *sc.RunAsUser != 70 || sc.RunAsGroup == nil || *sc.RunAsGroup != 70 {
+		t.Fatalf("Expected runAsUser/runAsGroup 70, got %+v", sc)
+	}
+	if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+		t.Error("Expected runAsNonRoot for a non-zero UID")
+	}
+	if mount := spec.Containers[0].VolumeMounts[0]; mount.MountPath != "/var/lib/postgresql/data" {
+		t.Errorf("Expected :U to be parsed as an option, got mountPath %s", mount.MountPath)
+	}
+
+	psc := spec.SecurityContext
+	if psc == nil {
+		t.Fatal("Expected pod securityContext")
+	}
+	if len(psc.SupplementalGroups) != 2 || psc.SupplementalGroups[0] != 34 || psc.SupplementalGroups[1] != 2000 {
+		t.Errorf("Unexpected supplementalGroups: %v", psc.SupplementalGroups)
+	}
+	if psc.FSGroup == nil || *psc.FSGroup != 70 {
+		t.Errorf("Expected fsGroup 70 from the :U volume, got %v", psc.FSGroup)
+	}
+}
+
+func TestConvertContainer_UserUIDGID(t *testing.T) {
+	input := `
+[Container]
+Image=app
+User=0:1000
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	sc := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].SecurityContext
+	if sc == nil || *sc.RunAsUser != 0 || *sc.RunAsGroup != 1000 {
+		t.Fatalf("Expected runAsUser 0 and runAsGroup 1000, got %+v", sc)
+	}
+	if sc.RunAsNonRoot != nil {
+		t.Error("runAsNonRoot must not be set for UID 0")
+	}
+}
+
+func TestConvertContainer_UserNameWithoutPasswd(t *testing.T) {
+	input := `
+[Container]
+Image=app
+User=nobody
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if sc := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].SecurityContext; sc != nil && sc.RunAsUser != nil {
+		t.Errorf("Expected no runAsUser for an unresolved name, got %d", *sc.RunAsUser)
+	}
+}
+
+func TestConvertContainer_SecurityOptions(t *testing.T) {
+	input := `
+[Container]
+Image=app
+SecurityLabelType=container_runtime_t
+SecurityLabelLevel=s0:c1,c2
+SeccompProfile=/etc/containers/seccomp/strict.json
+Unmask=ALL
+PodmanArgs=--privileged --security-opt apparmor=unconfined
+Volume=data.volume:/data:z
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+
+	sc := spec.Containers[0].SecurityContext
+	if sc == nil {
+		t.Fatal("Expected container securityContext")
+	}
+	if sc.SELinuxOptions == nil || sc.SELinuxOptions.Type != "container_runtime_t" || sc.SELinuxOptions.Level != "s0:c1,c2" {
+		t.Errorf("Unexpected seLinuxOptions: %+v", sc.SELinuxOptions)
+	}
+	if sc.SeccompProfile == nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeLocalhost || *sc.SeccompProfile.LocalhostProfile != "strict.json" {
+		t.Errorf("Unexpected seccompProfile: %+v", sc.SeccompProfile)
+	}
+	if sc.AppArmorProfile == nil || sc.AppArmorProfile.Type != corev1.AppArmorProfileTypeUnconfined {
+		t.Errorf("Unexpected appArmorProfile: %+v", sc.AppArmorProfile)
+	}
+	if sc.ProcMount == nil || *sc.ProcMount != corev1.UnmaskedProcMount {
+		t.Errorf("Expected procMount Unmasked, got %v", sc.ProcMount)
+	}
+	if sc.Privileged == nil || !*sc.Privileged {
+		t.Error("Expected privileged from PodmanArgs")
+	}
+
+	psc := spec.SecurityContext
+	if psc == nil || psc.SELinuxChangePolicy == nil || *psc.SELinuxChangePolicy != corev1.SELinuxChangePolicyRecursive {
+		t.Errorf("Expected seLinuxChangePolicy Recursive for :z, got %+v", psc)
+	}
+}
+
+func TestConvertContainer_SecurityLabelDisable(t *testing.T) {
+	input := `
+[Container]
+Image=app
+SecurityLabelDisable=true
+SeccompProfile=unconfined
+`
+	unit, _ := parser.Parse(strings.NewReader(input))
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	sc := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].SecurityContext
+	if sc == nil || sc.SELinuxOptions == nil || sc.SELinuxOptions.Type != "spc_t" {
+		t.Fatalf("Expected seLinuxOptions type spc_t, got %+v", sc)
+	}
+	if sc.SeccompProfile == nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeUnconfined {
+		t.Errorf("Expected Unconfined seccomp, got %+v", sc.SeccompProfile)
+	}
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Per-unit conversion settings are read from Label= or Annotation= entries
+// using these keys.
+const (
+	SettingServiceType = "kuadlet.io/service-type"
+)
+
+// containerSetting returns a kuadlet.io setting of a container unit,
+// preferring Annotation= over Label=.
+func containerSetting(c *quadlet.ContainerUnit, key string) string {
+	if v, ok := c.Container.Annotation[key]; ok {
+		return v
+	}
+	return c.Container.Label[key]
+}
+
+// podSetting returns a kuadlet.io setting of a pod unit.
+func podSetting(p *quadlet.PodUnit, key string) string {
+	return p.Pod.Label[key]
+}
+
+// resolveServiceType returns the per-unit service type if set, otherwise the
+// global default.
+func resolveServiceType(setting string, opts Options) (ServiceType, error) {
+	if setting == "" {
+		return opts.ServiceType, nil
+	}
+	t, err := ParseServiceType(setting)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", SettingServiceType, err)
+	}
+	return t, nil
+}
+
+// newService builds the Service exposing a unit's published ports. It returns
+// nil when there is nothing to expose; headless Services are still created
+// without ports so the unit gets a DNS name.
+func newService(name string, labels map[string]string, published *publishedPorts, serviceType ServiceType, opts Options) *corev1.Service {
+	var ports []corev1.ServicePort
+	if published != nil {
+		ports = published.Service
+	}
+	if len(ports) == 0 && serviceType != ServiceTypeHeadless {
+		return nil
+	}
+
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    ports,
+			Type:     serviceType.kubernetesType(),
+		},
+	}
+
+	safeName := sanitize(name)
+	switch serviceType {
+	case ServiceTypeHeadless:
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+		// Headless Services resolve straight to pod IPs, so clients must use
+		// the container port rather than the published host port.
+		for i := range service.Spec.Ports {
+			sp := &service.Spec.Ports[i]
+			if sp.Port != sp.TargetPort.IntVal {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: Headless Service %s: port %d is not remapped to %d; clients must connect to the container port.\n", safeName, sp.Port, sp.TargetPort.IntVal)
+			}
+		}
+	case ServiceTypeNodePort:
+		for i := range service.Spec.Ports {
+			sp := &service.Spec.Ports[i]
+			if sp.Port >= opts.NodePortMin && sp.Port <= opts.NodePortMax {
+				sp.NodePort = sp.Port
+			} else {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: Service %s: host port %d is outside the node port range %d-%d; a node port will be assigned by the cluster.\n", safeName, sp.Port, opts.NodePortMin, opts.NodePortMax)
+			}
+		}
+	case ServiceTypeLoadBalancer:
+		if published != nil && len(published.HostIPs) > 0 {
+			service.Spec.LoadBalancerIP = published.HostIPs[0] //nolint:staticcheck // deprecated, but still honoured by most providers
+			if len(published.HostIPs) > 1 {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: Service %s: ports are bound to multiple addresses %v; using %s as loadBalancerIP.\n", safeName, published.HostIPs, published.HostIPs[0])
+			}
+		}
+	}
+
+	return service
+}
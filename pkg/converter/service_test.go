@@ -0,0 +1,123 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func findService(objs []runtime.Object) *corev1.Service {
+	for _, obj := range objs {
+		if s, ok := obj.(*corev1.Service); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+func TestConvertContainer_NodePortService(t *testing.T) {
+	input := `
+[Container]
+Image=nginx
+PublishPort=30080:80
+PublishPort=8443:443
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "web", nil, &Options{ServiceType: ServiceTypeNodePort})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	service := findService(objs)
+	if service == nil {
+		t.Fatal("Service not found")
+	}
+	if service.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("Expected NodePort Service, got %s", service.Spec.Type)
+	}
+	if service.Spec.Ports[0].NodePort != 30080 {
+		t.Errorf("Expected nodePort 30080, got %d", service.Spec.Ports[0].NodePort)
+	}
+	// 8443 is outside the default node port range and left to the cluster.
+	if service.Spec.Ports[1].NodePort != 0 {
+		t.Errorf("Expected unset nodePort for 8443, got %d", service.Spec.Ports[1].NodePort)
+	}
+}
+
+func TestConvertContainer_LoadBalancerServiceFromUnit(t *testing.T) {
+	input := `
+[Container]
+Image=nginx
+Label=kuadlet.io/service-type=loadbalancer
+PublishPort=192.0.2.10:443:8443
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "web", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	service := findService(objs)
+	if service == nil {
+		t.Fatal("Service not found")
+	}
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		t.Errorf("Expected LoadBalancer Service, got %s", service.Spec.Type)
+	}
+	if service.Spec.LoadBalancerIP != "192.0.2.10" { //nolint:staticcheck
+		t.Errorf("Expected loadBalancerIP 192.0.2.10, got %q", service.Spec.LoadBalancerIP) //nolint:staticcheck
+	}
+}
+
+func TestConvertContainer_HeadlessServiceWithoutPorts(t *testing.T) {
+	input := `
+[Container]
+Image=postgres
+Annotation=kuadlet.io/service-type=Headless
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qContainer := quadlet.LoadContainer(unit)
+
+	objs, err := ConvertContainer(qContainer, "db", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+
+	service := findService(objs)
+	if service == nil {
+		t.Fatal("Expected headless Service for unit without ports")
+	}
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("Expected clusterIP None, got %q", service.Spec.ClusterIP)
+	}
+}
+
+func TestConvertPod_InvalidServiceType(t *testing.T) {
+	input := `
+[Pod]
+Label=kuadlet.io/service-type=External
+PublishPort=8080:80
+`
+	reader := strings.NewReader(input)
+	unit, _ := parser.Parse(reader)
+	qPod := quadlet.LoadPod(unit)
+
+	_, err := ConvertPod(qPod, nil, nil, "pod", nil, nil)
+	if err == nil {
+		t.Fatal("Expected error for unknown service type, got nil")
+	}
+	if !strings.Contains(err.Error(), SettingServiceType) {
+		t.Errorf("Expected service type error, got: %v", err)
+	}
+}
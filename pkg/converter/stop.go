@@ -0,0 +1,181 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// KubeVersion is the Kubernetes minor release manifests are generated for.
+// The zero value selects the most portable output.
+type KubeVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseKubeVersion parses a version such as 1.33, v1.33 or v1.33.2.
+func ParseKubeVersion(s string) (KubeVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q (expected e.g. 1.33)", s)
+	}
+	major, errMajor := strconv.Atoi(parts[0])
+	minor, errMinor := strconv.Atoi(parts[1])
+	if errMajor != nil || errMinor != nil {
+		return KubeVersion{}, fmt.Errorf("invalid Kubernetes version %q (expected e.g. 1.33)", s)
+	}
+	return KubeVersion{Major: major, Minor: minor}, nil
+}
+
+// AtLeast reports whether v is major.minor or later.
+func (v KubeVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || v.Major == major && v.Minor >= minor
+}
+
+// signalNumbers names the signals commonly given by number.
+var signalNumbers = map[string]string{
+	"1": "HUP", "2": "INT", "3": "QUIT", "6": "ABRT", "9": "KILL",
+	"10": "USR1", "12": "USR2", "15": "TERM", "28": "WINCH",
+}
+
+// signalName normalizes SIGQUIT, QUIT, quit and 3 to QUIT.
+func signalName(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if name, ok := signalNumbers[s]; ok {
+		return name
+	}
+	return strings.TrimPrefix(s, "SIG")
+}
+
+// systemdSeconds parses a systemd time span such as 90, 5min or 1min 30s into
+// whole seconds. "infinity" reports false.
+func systemdSeconds(s string) (int64, bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "infinity" {
+		return 0, false, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, true, nil
+	}
+	units := map[string]float64{
+		"us": 1e-6, "usec": 1e-6, "ms": 1e-3, "msec": 1e-3,
+		"s": 1, "sec": 1, "second": 1, "seconds": 1,
+		"m": 60, "min": 60, "minute": 60, "minutes": 60,
+		"h": 3600, "hr": 3600, "hour": 3600, "hours": 3600,
+		"d": 86400, "day": 86400, "days": 86400,
+	}
+	var total float64
+	rest := strings.ReplaceAll(s, " ", "")
+	for rest != "" {
+		split := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+		if split <= 0 {
+			return 0, false, fmt.Errorf("invalid time span %q", s)
+		}
+		end := strings.IndexFunc(rest[split:], func(r rune) bool { return unicode.IsDigit(r) })
+		if end < 0 {
+			end = len(rest) - split
+		}
+		n, err := strconv.ParseFloat(rest[:split], 64)
+		unit, ok := units[rest[split:split+end]]
+		if err != nil || !ok {
+			return 0, false, fmt.Errorf("invalid time span %q", s)
+		}
+		total += n * unit
+		rest = rest[split+end:]
+	}
+	return int64(total), true, nil
+}
+
+// stopTimeout returns how long a container gets to stop: StopTimeout= for
+// podman stop, bounded by the service's TimeoutStopSec=.
+func stopTimeout(c *quadlet.ContainerUnit) (int64, bool, error) {
+	var timeout int64
+	set := false
+	if c.Container.StopTimeout != "" {
+		n, err := strconv.ParseInt(c.Container.StopTimeout, 10, 64)
+		if err != nil || n < 0 {
+			return 0, false, fmt.Errorf("invalid StopTimeout %q", c.Container.StopTimeout)
+		}
+		timeout, set = n, true
+	}
+	if c.Service.TimeoutStopSec != "" {
+		n, ok, err := systemdSeconds(c.Service.TimeoutStopSec)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid TimeoutStopSec: %w", err)
+		}
+		if ok && (!set || n < timeout) {
+			timeout, set = n, true
+		}
+	}
+	return timeout, set, nil
+}
+
+// applyStopSettings maps stop timeouts to terminationGracePeriodSeconds, the
+// longest across the pod's containers, and non-default stop signals to
+// lifecycle.stopSignal on clusters supporting it (1.33+), or otherwise to a
+// preStop hook signalling PID 1, unless the process namespace is shared.
+// containers[i] is the unit of spec.Containers[i].
+func applyStopSettings(spec *corev1.PodSpec, containers []*quadlet.ContainerUnit, opts Options) error {
+	var grace int64
+	graceSet := false
+	for i, c := range containers {
+		container := &spec.Containers[i]
+
+		timeout, ok, err := stopTimeout(c)
+		if err != nil {
+			return fmt.Errorf("container %s: %w", container.Name, err)
+		}
+		if ok && (!graceSet || timeout > grace) {
+			grace, graceSet = timeout, true
+		}
+
+		if c.Container.StopSignal == "" {
+			continue
+		}
+		signal := signalName(c.Container.StopSignal)
+		if signal == "TERM" {
+			continue
+		}
+		if strings.IndexFunc(signal, func(r rune) bool { return !unicode.IsUpper(r) && !unicode.IsDigit(r) && r != '+' && r != '-' }) >= 0 {
+			return fmt.Errorf("container %s: invalid StopSignal %q", container.Name, c.Container.StopSignal)
+		}
+		if opts.KubeVersion.AtLeast(1, 33) {
+			if container.Lifecycle == nil {
+				container.Lifecycle = &corev1.Lifecycle{}
+			}
+			stopSignal := corev1.Signal("SIG" + signal)
+			container.Lifecycle.StopSignal = &stopSignal
+			spec.OS = &corev1.PodOS{Name: corev1.Linux}
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: StopSignal mapped to lifecycle.stopSignal, which requires the ContainerStopSignals feature gate.\n", sanitize(container.Name))
+			continue
+		}
+		if spec.ShareProcessNamespace != nil && *spec.ShareProcessNamespace {
+			// PID 1 is the pause process, so a hook cannot signal the app.
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: StopSignal=%s cannot be sent by a preStop hook with a shared process namespace (RunInit); the container receives SIGTERM. Use --kube-version 1.33 or later for lifecycle.stopSignal.\n", sanitize(container.Name), sanitize(c.Container.StopSignal))
+			continue
+		}
+		if container.Lifecycle == nil {
+			container.Lifecycle = &corev1.Lifecycle{}
+		}
+		// The kubelet sends SIGTERM once preStop returns, so wait for PID 1
+		// to exit on the configured signal first.
+		container.Lifecycle.PreStop = &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sh", "-c", fmt.Sprintf("kill -%s 1; while kill -0 1 2>/dev/null; do sleep 1; done", signal)},
+			},
+		}
+		// #nosec G705
+		fmt.Fprintf(os.Stderr, "Warning: %s: StopSignal=%s mapped to a preStop hook; the image must provide sh and kill. Use --kube-version 1.33 or later for lifecycle.stopSignal.\n", sanitize(container.Name), sanitize(c.Container.StopSignal))
+	}
+	if graceSet {
+		spec.TerminationGracePeriodSeconds = &grace
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestConvertPod_StopSettings(t *testing.T) {
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=nginx\nStopTimeout=60\nStopSignal=SIGQUIT\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=app\nStopTimeout=120\nStopSignal=TERM\n\n[Service]\nTimeoutStopSec=1min 30s\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2)}
+
+	objs, err := ConvertPod(quadlet.LoadPod(pUnit), containers, []string{"web", "app"}, "site", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+
+	// app is bounded by TimeoutStopSec to 90s, the longest in the pod.
+	if spec.TerminationGracePeriodSeconds == nil || *spec.TerminationGracePeriodSeconds != 90 {
+		t.Errorf("Expected terminationGracePeriodSeconds 90, got %v", spec.TerminationGracePeriodSeconds)
+	}
+	lc := spec.Containers[0].Lifecycle
+	if lc == nil || lc.PreStop == nil || lc.PreStop.Exec == nil || !strings.HasPrefix(lc.PreStop.Exec.Command[2], "kill -QUIT 1") {
+		t.Errorf("Expected a preStop hook sending SIGQUIT, got %+v", lc)
+	}
+	if spec.Containers[1].Lifecycle != nil {
+		t.Error("SIGTERM is the default and needs no hook")
+	}
+}
+
+func TestConvertContainer_StopSignalField(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=nginx\nStopSignal=3\n"))
+
+	objs, err := ConvertContainer(quadlet.LoadContainer(unit), "web", nil, &Options{KubeVersion: KubeVersion{Major: 1, Minor: 33}})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	lc := spec.Containers[0].Lifecycle
+	if lc == nil || lc.StopSignal == nil || *lc.StopSignal != corev1.SIGQUIT || lc.PreStop != nil {
+		t.Errorf("Expected lifecycle.stopSignal SIGQUIT, got %+v", lc)
+	}
+	if spec.OS == nil || spec.OS.Name != corev1.Linux {
+		t.Error("lifecycle.stopSignal requires spec.os.name")
+	}
+}
+
+func TestConvertContainer_StopSignalRunInit(t *testing.T) {
+	unit, _ := parser.Parse(strings.NewReader("[Container]\nImage=nginx\nStopSignal=SIGQUIT\nRunInit=true\n"))
+
+	objs, err := ConvertContainer(quadlet.LoadContainer(unit), "web", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	spec := objs[0].(*appsv1.Deployment).Spec.Template.Spec
+	if spec.ShareProcessNamespace == nil || !*spec.ShareProcessNamespace {
+		t.Fatal("Expected RunInit to share the process namespace")
+	}
+	if lc := spec.Containers[0].Lifecycle; lc != nil {
+		t.Errorf("PID 1 is the pause process; expected no preStop hook, got %+v", lc)
+	}
+
+	objs, err = ConvertContainer(quadlet.LoadContainer(unit), "web", nil, &Options{KubeVersion: KubeVersion{Major: 1, Minor: 33}})
+	if err != nil {
+		t.Fatalf("ConvertContainer failed: %v", err)
+	}
+	if lc := objs[0].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Lifecycle; lc == nil || lc.StopSignal == nil || *lc.StopSignal != corev1.SIGQUIT {
+		t.Errorf("Expected lifecycle.stopSignal SIGQUIT, got %+v", lc)
+	}
+}
+
+func TestSystemdSeconds(t *testing.T) {
+	cases := map[string]int64{"90": 90, "5min": 300, "1min 30s": 90, "2h": 7200, "500ms": 0}
+	for in, want := range cases {
+		got, ok, err := systemdSeconds(in)
+		if err != nil || !ok || got != want {
+			t.Errorf("systemdSeconds(%q) = %d, %v, %v; want %d", in, got, ok, err, want)
+		}
+	}
+	if _, ok, err := systemdSeconds("infinity"); ok || err != nil {
+		t.Error("infinity must report no timeout")
+	}
+	if _, _, err := systemdSeconds("5 fortnights"); err == nil {
+		t.Error("Expected an error for an unknown unit")
+	}
+}
@@ -0,0 +1,70 @@
+package converter
+
+import (
+	"fmt"
+	"kuadlet/pkg/quadlet"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// namespacedSysctl reports whether a sysctl is namespaced, and thus settable
+// per pod.
+func namespacedSysctl(name string) bool {
+	return strings.HasPrefix(name, "net.") ||
+		strings.HasPrefix(name, "fs.mqueue.") ||
+		strings.HasPrefix(name, "kernel.shm") ||
+		strings.HasPrefix(name, "kernel.msg") ||
+		name == "kernel.sem"
+}
+
+// applySysctls merges the Sysctl= settings of the pod's containers into
+// spec.securityContext.sysctls. Sysctls are per pod, so containers setting
+// different values are an error. containers[i] is the unit of
+// spec.Containers[i].
+func applySysctls(unit string, spec *corev1.PodSpec, containers []*quadlet.ContainerUnit) error {
+	values := make(map[string]string)
+	owners := make(map[string]string)
+	for i, c := range containers {
+		cName := spec.Containers[i].Name
+		for _, k := range sortedKeys(c.Container.Sysctl) {
+			v := c.Container.Sysctl[k]
+			if !namespacedSysctl(k) {
+				// #nosec G705
+				fmt.Fprintf(os.Stderr, "Warning: %s: Sysctl=%s=%s is not namespaced and cannot be set per pod; ignoring.\n", sanitize(cName), sanitize(k), sanitize(v))
+				continue
+			}
+			if prev, ok := values[k]; ok {
+				if prev != v {
+					return fmt.Errorf("%s: conflicting sysctl %s: %s sets %s, %s sets %s", unit, k, owners[k], prev, cName, v)
+				}
+				continue
+			}
+			values[k] = v
+			owners[k] = cName
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	for _, k := range names {
+		if !slices.Contains(safeSysctls, k) {
+			// #nosec G705
+			fmt.Fprintf(os.Stderr, "Warning: %s: sysctl %s is unsafe; the kubelet must allow it with --allowed-unsafe-sysctls or the pod is rejected.\n", sanitize(owners[k]), sanitize(k))
+		}
+		spec.SecurityContext.Sysctls = append(spec.SecurityContext.Sysctls, corev1.Sysctl{Name: k, Value: values[k]})
+	}
+	return nil
+}
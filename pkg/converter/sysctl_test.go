@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"kuadlet/pkg/parser"
+	"kuadlet/pkg/quadlet"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestConvertPod_SysctlMerge(t *testing.T) {
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=a\nSysctl=net.core.somaxconn=1024 net.ipv4.tcp_syncookies=1\nUlimit=nofile=65536:65536\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=b\nSysctl=net.core.somaxconn=1024\nSysctl=vm.max_map_count=262144\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2)}
+
+	objs, err := ConvertPod(quadlet.LoadPod(pUnit), containers, []string{"a", "b"}, "app", nil, nil)
+	if err != nil {
+		t.Fatalf("ConvertPod failed: %v", err)
+	}
+	psc := objs[0].(*appsv1.Deployment).Spec.Template.Spec.SecurityContext
+	if psc == nil || len(psc.Sysctls) != 2 {
+		t.Fatalf("Expected two merged namespaced sysctls, got %+v", psc)
+	}
+	if psc.Sysctls[0].Name != "net.core.somaxconn" || psc.Sysctls[0].Value != "1024" || psc.Sysctls[1].Name != "net.ipv4.tcp_syncookies" {
+		t.Errorf("Unexpected sysctls: %+v", psc.Sysctls)
+	}
+}
+
+func TestConvertPod_SysctlConflict(t *testing.T) {
+	pUnit, _ := parser.Parse(strings.NewReader("[Pod]\n"))
+	c1, _ := parser.Parse(strings.NewReader("[Container]\nImage=a\nSysctl=net.core.somaxconn=1024\n"))
+	c2, _ := parser.Parse(strings.NewReader("[Container]\nImage=b\nSysctl=net.core.somaxconn=4096\n"))
+	containers := []*quadlet.ContainerUnit{quadlet.LoadContainer(c1), quadlet.LoadContainer(c2)}
+
+	_, err := ConvertPod(quadlet.LoadPod(pUnit), containers, []string{"a", "b"}, "app", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "conflicting sysctl net.core.somaxconn") {
+		t.Errorf("Expected sysctl conflict error, got %v", err)
+	}
+}
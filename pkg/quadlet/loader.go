@@ -111,6 +111,16 @@ func LoadServiceSection(u *parser.Unit) ServiceSection {
 			s.Restart = opt.Value
 		case "TimeoutStartSec":
 			s.TimeoutStartSec = opt.Value
+		case "TimeoutStopSec":
+			s.TimeoutStopSec = opt.Value
+		case "CPUQuota":
+			s.CPUQuota = opt.Value
+		case "CPUWeight":
+			s.CPUWeight = opt.Value
+		case "MemoryLow":
+			s.MemoryLow = opt.Value
+		case "MemoryMax":
+			s.MemoryMax = opt.Value
 		default:
 			fmt.Fprintf(os.Stderr, "Warning: Unknown key in [Service]: %s\n", opt.Key)
 		}
@@ -137,12 +147,17 @@ func LoadContainerSection(u *parser.Unit) ContainerSection {
 		Environment: make(map[string]string),
 		Label:       make(map[string]string),
 		Annotation:  make(map[string]string),
+		Sysctl:      make(map[string]string),
 	}
 	opts := u.Sections["Container"]
 	for _, opt := range opts {
 		switch opt.Key {
 		case "Image":
 			c.Image = opt.Value
+		case "Pull":
+			c.Pull = opt.Value
+		case "AutoUpdate":
+			c.AutoUpdate = opt.Value
 		case "Exec":
 			c.Exec = opt.Value
 		case "Entrypoint":
@@ -154,14 +169,20 @@ func LoadContainerSection(u *parser.Unit) ContainerSection {
 			}
 		case "EnvironmentFile":
 			c.EnvironmentFile = append(c.EnvironmentFile, opt.Value)
+		case "ContainerName":
+			c.ContainerName = opt.Value
 		case "PublishPort":
 			c.PublishPort = append(c.PublishPort, opt.Value)
+		case "ExposeHostPort":
+			c.ExposeHostPort = append(c.ExposeHostPort, opt.Value)
 		case "Volume":
 			c.Volume = append(c.Volume, opt.Value)
 		case "User":
 			c.User = opt.Value
 		case "Group":
 			c.Group = opt.Value
+		case "GroupAdd":
+			c.GroupAdd = append(c.GroupAdd, opt.Value)
 		case "WorkingDir":
 			c.WorkingDir = opt.Value
 		case "Pod":
@@ -170,8 +191,24 @@ func LoadContainerSection(u *parser.Unit) ContainerSection {
 			c.Network = append(c.Network, opt.Value)
 		case "NetworkAlias":
 			c.NetworkAlias = append(c.NetworkAlias, opt.Value)
+		case "IP":
+			c.IP = opt.Value
+		case "IP6":
+			c.IP6 = opt.Value
 		case "HostName":
 			c.HostName = opt.Value
+		case "StopTimeout":
+			c.StopTimeout = opt.Value
+		case "StopSignal":
+			c.StopSignal = opt.Value
+		case "AddHost":
+			c.AddHost = append(c.AddHost, opt.Value)
+		case "DNS":
+			c.DNS = append(c.DNS, opt.Value)
+		case "DNSSearch":
+			c.DNSSearch = append(c.DNSSearch, opt.Value)
+		case "DNSOption":
+			c.DNSOption = append(c.DNSOption, opt.Value)
 		case "HealthCmd":
 			c.HealthCmd = opt.Value
 		case "HealthInterval":
@@ -184,8 +221,42 @@ func LoadContainerSection(u *parser.Unit) ContainerSection {
 			c.HealthTimeout = opt.Value
 		case "HealthStartPeriod":
 			c.HealthStartPeriod = opt.Value
+		case "HealthOnFailure":
+			c.HealthOnFailure = opt.Value
+		case "HealthStartupCmd":
+			c.HealthStartupCmd = opt.Value
+		case "HealthStartupInterval":
+			c.HealthStartupInterval = opt.Value
+		case "HealthStartupRetries":
+			if val, err := strconv.Atoi(opt.Value); err == nil {
+				c.HealthStartupRetries = val
+			}
+		case "HealthStartupSuccess":
+			if val, err := strconv.Atoi(opt.Value); err == nil {
+				c.HealthStartupSuccess = val
+			}
+		case "HealthStartupTimeout":
+			c.HealthStartupTimeout = opt.Value
+		case "Notify":
+			c.Notify = opt.Value
 		case "Memory":
 			c.Memory = opt.Value
+		case "ShmSize":
+			c.ShmSize = opt.Value
+		case "PidsLimit":
+			c.PidsLimit = opt.Value
+		case "Ulimit":
+			c.Ulimit = append(c.Ulimit, opt.Value)
+		case "Sysctl":
+			for _, s := range splitList(opt.Value) {
+				if k, v, ok := strings.Cut(s, "="); ok {
+					c.Sysctl[k] = v
+				}
+			}
+		case "AddDevice", "Device":
+			c.AddDevice = append(c.AddDevice, opt.Value)
+		case "Timezone":
+			c.Timezone = opt.Value
 		case "AddCapability":
 			c.AddCapability = append(c.AddCapability, splitList(opt.Value)...)
 		case "DropCapability":
@@ -196,6 +267,22 @@ func LoadContainerSection(u *parser.Unit) ContainerSection {
 			c.RunInit = parseBool(opt.Value)
 		case "ReadOnly":
 			c.ReadOnly = parseBool(opt.Value)
+		case "SecurityLabelDisable":
+			c.SecurityLabelDisable = parseBool(opt.Value)
+		case "SecurityLabelFileType":
+			c.SecurityLabelFileType = opt.Value
+		case "SecurityLabelLevel":
+			c.SecurityLabelLevel = opt.Value
+		case "SecurityLabelType":
+			c.SecurityLabelType = opt.Value
+		case "SeccompProfile":
+			c.SeccompProfile = opt.Value
+		case "AppArmor":
+			c.AppArmor = opt.Value
+		case "Mask":
+			c.Mask = append(c.Mask, strings.Split(opt.Value, ":")...)
+		case "Unmask":
+			c.Unmask = append(c.Unmask, strings.Split(opt.Value, ":")...)
 		case "Label":
 			parts := strings.SplitN(opt.Value, "=", 2)
 			if len(parts) == 2 {
@@ -212,16 +299,23 @@ func LoadContainerSection(u *parser.Unit) ContainerSection {
 			fmt.Fprintf(os.Stderr, "Warning: Unknown key in [Container]: %s\n", opt.Key)
 		}
 	}
+	// PodmanArgs are appended to the generated podman run command line, so
+	// they override the keys above.
+	c.PodmanArgs = c.applyPodmanArgs(c.PodmanArgs)
 	return c
 }
 
 func LoadPodSection(u *parser.Unit) PodSection {
-	p := PodSection{}
+	p := PodSection{
+		Label: make(map[string]string),
+	}
 	opts := u.Sections["Pod"]
 	for _, opt := range opts {
 		switch opt.Key {
 		case "PodName":
 			p.PodName = opt.Value
+		case "HostName":
+			p.HostName = opt.Value
 		case "PublishPort":
 			p.PublishPort = append(p.PublishPort, opt.Value)
 		case "Volume":
@@ -232,6 +326,21 @@ func LoadPodSection(u *parser.Unit) PodSection {
 			p.NetworkAlias = append(p.NetworkAlias, opt.Value)
 		case "IP":
 			p.IP = opt.Value
+		case "IP6":
+			p.IP6 = opt.Value
+		case "AddHost":
+			p.AddHost = append(p.AddHost, opt.Value)
+		case "DNS":
+			p.DNS = append(p.DNS, opt.Value)
+		case "DNSSearch":
+			p.DNSSearch = append(p.DNSSearch, opt.Value)
+		case "DNSOption":
+			p.DNSOption = append(p.DNSOption, opt.Value)
+		case "Label":
+			parts := strings.SplitN(opt.Value, "=", 2)
+			if len(parts) == 2 {
+				p.Label[parts[0]] = parts[1]
+			}
 		case "GlobalArgs":
 			p.GlobalArgs = append(p.GlobalArgs, splitArgs(opt.Value)...)
 		case "PodmanArgs":
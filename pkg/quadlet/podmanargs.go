@@ -0,0 +1,151 @@
+package quadlet
+
+import "strings"
+
+// containerValueFlags are the podman run flags taking a value that
+// applyPodmanArgs folds into ContainerSection, keyed by long and short name.
+var containerValueFlags = map[string]func(c *ContainerSection, v string) bool{
+	"--cpus":               func(c *ContainerSection, v string) bool { c.CPUs = v; return true },
+	"--cpu-shares":         func(c *ContainerSection, v string) bool { c.CPUShares = v; return true },
+	"-c":                   func(c *ContainerSection, v string) bool { c.CPUShares = v; return true },
+	"--memory":             func(c *ContainerSection, v string) bool { c.Memory = v; return true },
+	"-m":                   func(c *ContainerSection, v string) bool { c.Memory = v; return true },
+	"--memory-reservation": func(c *ContainerSection, v string) bool { c.MemoryReservation = v; return true },
+	"--shm-size":           func(c *ContainerSection, v string) bool { c.ShmSize = v; return true },
+	"--pids-limit":         func(c *ContainerSection, v string) bool { c.PidsLimit = v; return true },
+	"--device":             func(c *ContainerSection, v string) bool { c.AddDevice = append(c.AddDevice, v); return true },
+	"--ulimit":             func(c *ContainerSection, v string) bool { c.Ulimit = append(c.Ulimit, v); return true },
+	"--stop-timeout":       func(c *ContainerSection, v string) bool { c.StopTimeout = v; return true },
+	"--stop-signal":        func(c *ContainerSection, v string) bool { c.StopSignal = v; return true },
+	"--pull":               func(c *ContainerSection, v string) bool { c.Pull = v; return true },
+	"--tz":                 func(c *ContainerSection, v string) bool { c.Timezone = v; return true },
+	"--add-host":           func(c *ContainerSection, v string) bool { c.AddHost = append(c.AddHost, v); return true },
+	"--cap-add": func(c *ContainerSection, v string) bool {
+		c.AddCapability = append(c.AddCapability, strings.Split(v, ",")...)
+		return true
+	},
+	"--cap-drop": func(c *ContainerSection, v string) bool {
+		c.DropCapability = append(c.DropCapability, strings.Split(v, ",")...)
+		return true
+	},
+	"--sysctl": func(c *ContainerSection, v string) bool {
+		k, val, ok := strings.Cut(v, "=")
+		if ok {
+			c.Sysctl[k] = val
+		}
+		return ok
+	},
+	"--security-opt": (*ContainerSection).applySecurityOpt,
+}
+
+// podmanValueFlags are the other podman run flags taking a value, so that
+// "--flag value" is kept together when left over. Flags in neither table are
+// boolean and never consume the next argument.
+var podmanValueFlags = map[string]bool{
+	"--annotation": true, "--arch": true, "--attach": true, "-a": true, "--authfile": true,
+	"--blkio-weight": true, "--blkio-weight-device": true, "--cgroup-conf": true,
+	"--cgroup-parent": true, "--cgroupns": true, "--cgroups": true, "--chrootdirs": true,
+	"--cidfile": true, "--conmon-pidfile": true, "--cpu-period": true, "--cpu-quota": true,
+	"--cpu-rt-period": true, "--cpu-rt-runtime": true, "--cpuset-cpus": true,
+	"--cpuset-mems": true, "--creds": true, "--decryption-key": true, "--detach-keys": true,
+	"--device-cgroup-rule": true, "--device-read-bps": true, "--device-read-iops": true,
+	"--device-write-bps": true, "--device-write-iops": true, "--dns": true,
+	"--dns-option": true, "--dns-search": true, "--entrypoint": true, "--env": true, "-e": true,
+	"--env-file": true, "--env-merge": true, "--expose": true, "--gidmap": true, "--gpus": true,
+	"--group-add": true, "--group-entry": true, "--health-cmd": true, "--health-interval": true,
+	"--health-log-destination": true, "--health-max-log-count": true,
+	"--health-max-log-size": true, "--health-on-failure": true, "--health-retries": true,
+	"--health-start-period": true, "--health-startup-cmd": true,
+	"--health-startup-interval": true, "--health-startup-retries": true,
+	"--health-startup-success": true, "--health-startup-timeout": true,
+	"--health-timeout": true, "--hostname": true, "-h": true, "--hosts-file": true,
+	"--hostuser": true, "--image-volume": true, "--init-path": true, "--ip": true,
+	"--ip6": true, "--ipc": true, "--label": true, "-l": true, "--label-file": true,
+	"--log-driver": true, "--log-opt": true, "--mac-address": true, "--memory-swap": true,
+	"--memory-swappiness": true, "--mount": true, "--name": true, "--network": true,
+	"--net": true, "--network-alias": true, "--oom-score-adj": true, "--os": true,
+	"--passwd-entry": true, "--personality": true, "--pid": true, "--pidfile": true,
+	"--platform": true, "--pod": true, "--pod-id-file": true, "--preserve-fd": true,
+	"--preserve-fds": true, "--publish": true, "-p": true, "--requires": true,
+	"--restart": true, "--retry": true, "--retry-delay": true, "--sdnotify": true,
+	"--seccomp-policy": true, "--secret": true, "--shm-size-systemd": true,
+	"--subgidname": true, "--subuidname": true, "--systemd": true, "--timeout": true,
+	"--tmpfs": true, "--uidmap": true, "--umask": true, "--unsetenv": true, "--user": true,
+	"-u": true, "--userns": true, "--uts": true, "--variant": true, "--volume": true,
+	"-v": true, "--volumes-from": true, "--workdir": true, "-w": true,
+}
+
+// containerBoolFlags are the boolean podman run flags folded into
+// ContainerSection.
+var containerBoolFlags = map[string]func(c *ContainerSection, v bool){
+	"--privileged": func(c *ContainerSection, v bool) { c.Privileged = v },
+	"--init":       func(c *ContainerSection, v bool) { c.RunInit = v },
+	"--read-only":  func(c *ContainerSection, v bool) { c.ReadOnly = v },
+}
+
+// applyPodmanArgs folds the podman run flags it understands into c and
+// returns the remaining ones. Flags accept both "--flag value" and
+// "--flag=value"; later flags override earlier ones as they do in podman.
+func (c *ContainerSection) applyPodmanArgs(args []string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		start := i
+		name, value, hasValue := strings.Cut(args[i], "=")
+
+		if set, ok := containerBoolFlags[name]; ok {
+			switch {
+			case !hasValue:
+				set(c, true)
+			case value == "true" || value == "false":
+				set(c, value == "true")
+			default:
+				rest = append(rest, args[i])
+			}
+			continue
+		}
+
+		set, ok := containerValueFlags[name]
+		if !hasValue && i+1 < len(args) && (ok || podmanValueFlags[name]) {
+			i++
+			value, hasValue = args[i], true
+		}
+		if !ok || !hasValue || !set(c, value) {
+			rest = append(rest, args[start:i+1]...)
+		}
+	}
+	return rest
+}
+
+// applySecurityOpt folds a --security-opt value into c.
+func (c *ContainerSection) applySecurityOpt(opt string) bool {
+	key, value, _ := strings.Cut(opt, "=")
+	switch key {
+	case "label":
+		kind, v, _ := strings.Cut(value, ":")
+		switch kind {
+		case "disable":
+			c.SecurityLabelDisable = true
+		case "type":
+			c.SecurityLabelType = v
+		case "level":
+			c.SecurityLabelLevel = v
+		case "filetype":
+			c.SecurityLabelFileType = v
+		default:
+			return false
+		}
+	case "seccomp":
+		c.SeccompProfile = value
+	case "apparmor":
+		c.AppArmor = value
+	case "mask":
+		c.Mask = append(c.Mask, strings.Split(value, ":")...)
+	case "unmask":
+		c.Unmask = append(c.Unmask, strings.Split(value, ":")...)
+	case "no-new-privileges":
+		c.NoNewPrivileges = value == "" || value == "true"
+	default:
+		return false
+	}
+	return true
+}
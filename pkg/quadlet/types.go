@@ -67,6 +67,13 @@ type UnitSection struct {
 type ServiceSection struct {
 	Restart         string
 	TimeoutStartSec string
+	TimeoutStopSec  string
+
+	// Resource control of the service's cgroup.
+	CPUQuota  string
+	CPUWeight string
+	MemoryLow string
+	MemoryMax string
 }
 
 type InstallSection struct {
@@ -75,29 +82,61 @@ type InstallSection struct {
 
 type ContainerSection struct {
 	Image             string
+	Pull              string
+	AutoUpdate        string
+	ContainerName     string
 	Exec              string // Can be multiple words
 	Entrypoint        string
 	Environment       map[string]string
 	EnvironmentFile   []string
 	PublishPort       []string
+	ExposeHostPort    []string
 	Volume            []string
 	User              string
 	Group             string
+	GroupAdd          []string
 	WorkingDir        string
 	Pod               string
 	Network           []string
 	NetworkAlias      []string
+	IP                string
+	IP6               string
 	HostName          string
+	StopTimeout       string
+	StopSignal        string
+	AddHost           []string
+	DNS               []string
+	DNSSearch         []string
+	DNSOption         []string
 
 	// Health Check
-	HealthCmd         string
-	HealthInterval    string
-	HealthRetries     int
-	HealthTimeout     string
-	HealthStartPeriod string
+	HealthCmd             string
+	HealthInterval        string
+	HealthRetries         int
+	HealthTimeout         string
+	HealthStartPeriod     string
+	HealthOnFailure       string
+	HealthStartupCmd      string
+	HealthStartupInterval string
+	HealthStartupRetries  int
+	HealthStartupSuccess  int
+	HealthStartupTimeout  string
+	Notify                string
 
 	// Resources
-	Memory string
+	Memory    string
+	ShmSize   string
+	PidsLimit string
+	Ulimit    []string
+	Sysctl    map[string]string
+	AddDevice []string
+	Timezone  string
+
+	// Only settable through PodmanArgs.
+	CPUs              string
+	CPUShares         string
+	MemoryReservation string
+	Privileged        bool
 
 	// Security
 	AddCapability   []string
@@ -106,21 +145,38 @@ type ContainerSection struct {
 	RunInit         bool
 	ReadOnly        bool
 
+	SecurityLabelDisable  bool
+	SecurityLabelFileType string
+	SecurityLabelLevel    string
+	SecurityLabelType     string
+	SeccompProfile        string
+	AppArmor              string
+	Mask                  []string
+	Unmask                []string
+
 	// Metadata
 	Label      map[string]string
 	Annotation map[string]string
 
 	// Advanced
+	// PodmanArgs holds the flags not folded into the fields above.
 	PodmanArgs []string
 }
 
 type PodSection struct {
 	PodName      string
+	HostName     string
 	PublishPort  []string
 	Volume       []string
 	Network      []string
 	NetworkAlias []string
 	IP           string
+	IP6          string
+	AddHost      []string
+	DNS          []string
+	DNSSearch    []string
+	DNSOption    []string
+	Label        map[string]string
 	GlobalArgs   []string
 	PodmanArgs   []string
 }